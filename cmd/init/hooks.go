@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/hooks"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
+	"github.com/TheRealSibasishBehera/init-go/internal/supervisor"
+	"github.com/TheRealSibasishBehera/init-go/internal/user"
+)
+
+// runHooks runs the hooks of phase with the user, environment and working
+// directory of proc.
+func runHooks(cfg *config.RunConfig, proc config.ProcessConfig, phase string, hk *hooks.Runner) error {
+	hookConfigs := cfg.GetHooks(phase)
+	if len(hookConfigs) == 0 {
+		return nil
+	}
+
+	u, err := user.Lookup(proc.User)
+	if err != nil {
+		return fmt.Errorf("failed to resolve user of %s hooks: %w", phase, err)
+	}
+	return hk.Run(phase, hookConfigs, processEnv(cfg, proc, u), proc.WorkingDir, u.Credential())
+}
+
+// abortBoot stops whatever has been started so far and shuts the guest down
+// after a boot phase failed, dropping into the rescue shell first if
+// enabled.
+func abortBoot(cfg *config.RunConfig, processes []config.ProcessConfig, r *reaper.Reaper, sup *supervisor.Supervisor, hk *hooks.Runner, err error) {
+	log.Printf("FATAL: %v", err)
+	stopProcesses(cfg, processes, r, sup, hk)
+	if rescueOnFailure(cfg) {
+		rescue(cfg, r, "boot failed")
+	}
+	shutdown(cfg, r, sup)
+}
@@ -2,103 +2,298 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"os/signal"
-	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/etc"
+	"github.com/TheRealSibasishBehera/init-go/internal/files"
+	"github.com/TheRealSibasishBehera/init-go/internal/hooks"
+	"github.com/TheRealSibasishBehera/init-go/internal/network"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
+	"github.com/TheRealSibasishBehera/init-go/internal/secrets"
 	"github.com/TheRealSibasishBehera/init-go/internal/server"
+	"github.com/TheRealSibasishBehera/init-go/internal/supervisor"
 	"github.com/TheRealSibasishBehera/init-go/internal/system"
+	"github.com/TheRealSibasishBehera/init-go/internal/user"
+	"github.com/mdlayher/vsock"
 )
 
-const DefaultConfigPath = "/fly/run.json"
+const (
+	// ConfigVSockPort is the host port init asks for its configuration
+	// when no other config source has one.
+	ConfigVSockPort = 1001
 
-var waitPidMutex sync.Mutex
+	configVSockTimeout = 10 * time.Second
+)
+
+// appConsole is the console relay of the application while it runs on a
+// PTY, so that the dispatcher can pass on window size changes.
+var appConsole atomic.Pointer[system.Console]
 
 func main() {
 	if err := validatePID1(); err != nil {
 		log.Fatalf("FATAL: Not running as PID 1: %v", err)
 	}
 
-	cfg, err := loadConfiguration()
+	// the reaper is needed as soon as anything can be started, including
+	// the rescue shell on a failed boot
+	r := reaper.New()
+	go r.Run()
+
+	if err := system.MountEssential(); err != nil {
+		bootFailed(nil, r, err)
+	}
+
+	cfg, source, err := loadConfiguration()
 	if err != nil {
-		log.Fatalf("FATAL: Configuration error: %v", err)
+		bootFailed(nil, r, fmt.Errorf("Configuration error: %w", err))
 	}
+	// keep secret values out of everything init logs from here on,
+	// including the output of hooks
+	log.SetOutput(secrets.NewRedactor(cfg.GetSecretValues()).Writer(os.Stderr))
 	log.Printf("Loaded configuration: hostname=%s", cfg.Hostname)
 
-	system.MountEssential(cfg)
+	if err := system.SetHostname(cfg.Hostname); err != nil {
+		bootFailed(cfg, r, err)
+	}
 
-	signals := make(chan os.Signal, 1)
-	signal.Notify(signals, syscall.SIGTERM, syscall.SIGCHLD, syscall.SIGINT)
+	for _, rlimit := range cfg.Rlimits {
+		resource, err := config.ParseRlimit(rlimit.Type)
+		if err == nil {
+			err = system.SetRlimit(resource, rlimit.Soft, rlimit.Hard)
+		}
+		if err != nil {
+			bootFailed(cfg, r, fmt.Errorf("cannot set %s to %d/%d: %w", rlimit.Type, rlimit.Soft, rlimit.Hard, err))
+		}
+		log.Printf("Set %s to soft=%d hard=%d", rlimit.Type, rlimit.Soft, rlimit.Hard)
+	}
+
+	if err := network.Configure(cfg); err != nil {
+		bootFailed(cfg, r, err)
+	}
+	if err := etc.Write(cfg); err != nil {
+		bootFailed(cfg, r, err)
+	}
+
+	// Subscribe to every signal: everything but SIGCHLD, which the reaper
+	// handles, is forwarded to the application.
+	signals := make(chan os.Signal, signalBufferSize)
+	signal.Notify(signals)
+
+	processes, err := cfg.GetStartOrder()
+	if err != nil {
+		bootFailed(cfg, r, err)
+	}
+	// resolve every user up front so a typo fails the boot before anything
+	// has been started
+	var appUser *user.User
+	for i, proc := range processes {
+		u, err := user.Lookup(proc.User)
+		if err != nil {
+			bootFailed(cfg, r, fmt.Errorf("process %s: %w", proc.Name, err))
+		}
+		if proc.Name == config.AppProcessName {
+			appUser = u
+			// $HOME in cmdOverride is the home of the application's user
+			processes[i].Command = cfg.GetCommandForHome(u.Home)
+		}
+	}
+
+	if secrets.HasFiles(cfg.Secrets) {
+		if err := system.MountSecrets(config.SecretsDir); err != nil {
+			bootFailed(cfg, r, err)
+		}
+		if err := secrets.Install(config.SecretsDir, cfg.Secrets, appUser); err != nil {
+			bootFailed(cfg, r, err)
+		}
+		log.Printf("Installed secrets in %s", config.SecretsDir)
+	}
+
+	if err := files.Write(cfg.Files); err != nil {
+		bootFailed(cfg, r, err)
+	}
+
+	if rescueRequested() {
+		rescue(cfg, r, "requested on the kernel command line")
+	}
+
+	sup := supervisor.New(processes)
+
+	hk := hooks.New(r, secrets.NewRedactor(cfg.GetSecretValues()))
 
 	go func() {
-		server.StartVSocServer(&waitPidMutex, cfg.ExtraEnv)
+		// commands run over the API see the application's environment,
+		// without its secrets
+		apiEnv := cfg.GetProcessEnvironment(nil, appUser.Home)
+		server.StartVSocServer(r, apiEnv, sup, hk, cfg, source)
 	}()
 	log.Printf("Started VSOCK server on port %d", server.VSockPort)
 
-	var cmd *exec.Cmd
-	if command := cfg.GetCommand(); len(command) > 0 {
-		cmd = exec.Command(command[0], command[1:]...)
-		if env := cfg.GetEnvironment(); len(env) > 0 {
-			cmd.Env = append(os.Environ(), env...)
+	// the API is already up so a guest stuck here can be inspected
+	if cfg.WaitForNetwork != nil {
+		if err := network.Wait(cfg); err != nil {
+			if cfg.WaitForNetwork.GetOnTimeout() != config.NetworkWaitWarn {
+				bootFailed(cfg, r, err)
+			}
+			log.Printf("Starting the application anyway: %v", err)
 		}
-		cmd.Dir = cfg.GetWorkingDir()
+	}
 
-		if err := cmd.Start(); err != nil {
-			log.Fatalf("FATAL: Failed to start command %v: %v", command, err)
+	// exits carries the names of supervised processes that have been
+	// reaped to the dispatcher.
+	exits := make(chan string, len(processes))
+	for _, proc := range processes {
+		isApp := proc.Name == config.AppProcessName
+		if isApp {
+			if err := runHooks(cfg, proc, config.HookPreStart, hk); err != nil {
+				abortBoot(cfg, processes, r, sup, hk, err)
+			}
+		}
+		if err := startProcess(cfg, proc, r, sup, exits); err != nil {
+			abortBoot(cfg, processes, r, sup, hk, err)
+		}
+		if isApp {
+			if err := runHooks(cfg, proc, config.HookPostStart, hk); err != nil {
+				abortBoot(cfg, processes, r, sup, hk, err)
+			}
 		}
-		log.Printf("Started application %v with PID %d", command, cmd.Process.Pid)
-	} else {
-		log.Fatalf("FATAL: No command specified in configuration")
 	}
 
 	log.Println("Init system ready, entering main loop...")
 
-	for sig := range signals {
-		switch sig {
-		case syscall.SIGTERM, syscall.SIGINT:
-			log.Printf("Received %s, shutting down gracefully...", sig)
-			if cmd != nil && cmd.Process != nil {
-				log.Printf("Terminating child process %d", cmd.Process.Pid)
-				cmd.Process.Signal(syscall.SIGTERM)
-			}
-			os.Exit(0)
+	dispatchSignals(signals, exits, cfg, processes, r, sup, hk)
+}
+
+// startProcess launches a supervised process and registers it with the
+// supervisor. It is used both at boot and when a restart policy relaunches
+// the process. Once the process is reaped its exit is recorded and its name
+// is sent on exits.
+func startProcess(cfg *config.RunConfig, proc config.ProcessConfig, r *reaper.Reaper, sup *supervisor.Supervisor, exits chan<- string) error {
+	if len(proc.Command) == 0 {
+		return fmt.Errorf("no command specified for process %s", proc.Name)
+	}
+
+	u, err := user.Lookup(proc.User)
+	if err != nil {
+		return fmt.Errorf("failed to resolve user of process %s: %w", proc.Name, err)
+	}
 
-		case syscall.SIGCHLD:
-			log.Println("Received SIGCHLD, reaping zombies...")
-			reapZombies(&waitPidMutex)
+	cmd := exec.Command(proc.Command[0], proc.Command[1:]...)
+	cmd.Env = processEnv(cfg, proc, u)
+	cmd.Dir = proc.WorkingDir
+	// Give every process its own process group so signals can be
+	// forwarded to it and all of its children at once.
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:    true,
+		Credential: u.Credential(),
+	}
+	// root has every capability already
+	if proc.Name == config.AppProcessName && u.Uid != 0 && len(cfg.Capabilities) > 0 {
+		caps, err := cfg.GetCapabilities()
+		if err != nil {
+			return fmt.Errorf("failed to resolve capabilities of process %s: %w", proc.Name, err)
 		}
+		system.SetAmbientCaps(cmd.SysProcAttr, caps)
 	}
-}
 
-func reapZombies(waitPidMutex *sync.Mutex) {
-	waitPidMutex.Lock()
-	defer waitPidMutex.Unlock()
+	var console *system.Console
+	if cfg.TTY && proc.Name == config.AppProcessName {
+		master, slave, err := system.OpenPTY()
+		if err != nil {
+			return fmt.Errorf("failed to allocate a tty for process %s: %w", proc.Name, err)
+		}
+		defer slave.Close()
+
+		console, err = system.AttachConsole(master, system.ConsolePath)
+		if err != nil {
+			master.Close()
+			return fmt.Errorf("failed to attach the tty of process %s to the console: %w", proc.Name, err)
+		}
+
+		cmd.Stdin = slave
+		cmd.Stdout = slave
+		cmd.Stderr = slave
+		// A new session also puts the process in its own process group,
+		// which is why Setpgid must not be set as well.
+		cmd.SysProcAttr.Setpgid = false
+		cmd.SysProcAttr.Setsid = true
+		cmd.SysProcAttr.Setctty = true
+		cmd.SysProcAttr.Ctty = 0
+	}
 
-	for {
-		var status syscall.WaitStatus
-		pid, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
-		if err != nil || pid == 0 {
-			break
+	process, err := r.Start(cmd)
+	if err != nil {
+		if console != nil {
+			console.Close()
 		}
-		log.Printf("Reaped zombie process %d with status %d", pid, status)
+		return fmt.Errorf("failed to start process %s %v: %w", proc.Name, proc.Command, err)
 	}
+	if console != nil {
+		appConsole.Store(console)
+	}
+	sup.Started(proc.Name, proc.Command, process.Pid)
+	log.Printf("Started process %s %v with PID %d as uid=%d gid=%d", proc.Name, proc.Command, process.Pid, u.Uid, u.Gid)
+
+	go func() {
+		status := process.Wait()
+		log.Printf("Reaped process %s (PID %d) with status %d", proc.Name, process.Pid, status)
+		sup.Exited(process.Pid, status)
+		if console != nil {
+			appConsole.CompareAndSwap(console, nil)
+			console.Close()
+		}
+		exits <- proc.Name
+	}()
+	return nil
+}
+
+// processEnv returns the environment of a supervised process, with HOME
+// defaulting to its user's home directory.
+func processEnv(cfg *config.RunConfig, proc config.ProcessConfig, u *user.User) []string {
+	return cfg.GetProcessEnvironment(proc.Env, u.Home)
 }
 
-func loadConfiguration() (*config.RunConfig, error) {
-	cfg, err := config.LoadConfig(DefaultConfigPath)
+func loadConfiguration() (*config.RunConfig, config.Source, error) {
+	params, err := system.KernelParams()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load configuration from %s: %w", DefaultConfigPath, err)
+		log.Printf("Failed to read the kernel command line: %v", err)
 	}
 
+	loader := &config.Loader{
+		Cmdline:  params,
+		Getenv:   os.Getenv,
+		DialHost: dialConfigHost,
+		Lenient:  params[config.CmdlineConfigLenientParam] == "1",
+	}
+	cfg, source, err := loader.Load()
+	if err != nil {
+		return nil, source, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	log.Printf("Loaded configuration from %s", source)
+
 	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
+		return nil, source, fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	return cfg, nil
+	return cfg, source, nil
+}
+
+// dialConfigHost connects to the host's config port over vsock.
+func dialConfigHost() (io.ReadCloser, string, error) {
+	location := fmt.Sprintf("host:%d", ConfigVSockPort)
+	conn, err := vsock.Dial(vsock.Host, ConfigVSockPort, nil)
+	if err != nil {
+		return nil, location, err
+	}
+	conn.SetDeadline(time.Now().Add(configVSockTimeout))
+	return conn, location, nil
 }
 
 func validatePID1() error {
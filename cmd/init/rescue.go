@@ -0,0 +1,87 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
+	"github.com/TheRealSibasishBehera/init-go/internal/system"
+)
+
+// rescueParam is the kernel command line parameter that starts the rescue
+// shell before the application when set to 1.
+const rescueParam = "init.rescue"
+
+// rescueRequested reports whether the rescue shell was requested on the
+// kernel command line.
+func rescueRequested() bool {
+	value, _ := system.KernelParam(rescueParam)
+	return value == "1"
+}
+
+// rescueOnFailure reports whether a failed boot phase should drop into the
+// rescue shell. cfg is nil if the configuration could not be loaded.
+func rescueOnFailure(cfg *config.RunConfig) bool {
+	if cfg != nil && cfg.Rescue != nil && cfg.Rescue.OnFailure {
+		return true
+	}
+	return rescueRequested()
+}
+
+// rescue runs the rescue shell on the console and waits for it to exit.
+func rescue(cfg *config.RunConfig, r *reaper.Reaper, reason string) {
+	shell := config.DefaultRescueShell
+	if cfg != nil {
+		shell = cfg.GetRescueShell()
+	}
+	log.Printf("Starting rescue shell %s: %s", shell, reason)
+
+	cmd := exec.Command(shell)
+	cmd.Env = os.Environ()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// make the console the shell's controlling terminal so that job
+	// control and ^C work
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid:  true,
+		Setctty: system.IsTerminal(os.Stdin),
+	}
+
+	process, err := r.Start(cmd)
+	if err != nil {
+		log.Printf("Failed to start rescue shell %s: %v", shell, err)
+		return
+	}
+
+	log.Printf("Rescue shell exited with status %d", process.Wait())
+}
+
+// bootFailed handles a boot phase failing before any supervised process was
+// started. It drops into the rescue shell if enabled and then shuts the guest
+// down, rebooting it if cfg is nil because the configuration could not be
+// loaded.
+func bootFailed(cfg *config.RunConfig, r *reaper.Reaper, err error) {
+	log.Printf("FATAL: %v", err)
+	if rescueOnFailure(cfg) {
+		rescue(cfg, r, "boot failed")
+	}
+
+	// commands run over the API may have been started already
+	terminateRemaining(r, shutdownGracePeriod)
+
+	action := config.ShutdownReboot
+	if cfg != nil {
+		action = cfg.GetShutdownAction()
+	}
+	log.Printf("Shutting down guest: %s", action)
+	if err := system.Shutdown(action); err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	// Only reached on platforms where Shutdown is a stub.
+	os.Exit(1)
+}
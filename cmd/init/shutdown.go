@@ -0,0 +1,152 @@
+package main
+
+import (
+	"log"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/hooks"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
+	"github.com/TheRealSibasishBehera/init-go/internal/supervisor"
+	"github.com/TheRealSibasishBehera/init-go/internal/system"
+)
+
+// shutdownGracePeriod is how long leftover processes get to exit after
+// SIGTERM before they are killed.
+const shutdownGracePeriod = 5 * time.Second
+
+// shutdown runs once the supervised processes have stopped: it stops every
+// remaining process, waits up to the configured linger for the application's
+// final status to be read from /v1/app and then powers off or reboots the
+// guest.
+func shutdown(cfg *config.RunConfig, r *reaper.Reaper, sup *supervisor.Supervisor) {
+	for _, status := range sup.Processes() {
+		if status.Exit == nil {
+			log.Printf("Process %s (PID %d) has not exited, shutting down anyway", status.Name, status.Pid)
+			continue
+		}
+		log.Printf("Process %s (PID %d) %s", status.Name, status.Pid, status.Exit.Reason)
+	}
+
+	code := 0
+	if app := sup.App(); app.Exit != nil {
+		code = app.Exit.Code()
+	}
+
+	terminateRemaining(r, shutdownGracePeriod)
+
+	linger := cfg.GetShutdownLinger()
+	if !sup.WaitAppReported(linger) {
+		log.Printf("Final status of the application was not read within %s", linger)
+	}
+
+	action := cfg.GetShutdownAction()
+	log.Printf("Shutting down guest: %s", action)
+	if err := system.Shutdown(action); err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	// Only reached on platforms where Shutdown is a stub.
+	os.Exit(code)
+}
+
+// terminateRemaining sends SIGTERM to every process but init, waits up to
+// timeout for them to be reaped and then sends SIGKILL to the rest.
+func terminateRemaining(r *reaper.Reaper, timeout time.Duration) {
+	if err := syscall.Kill(-1, syscall.SIGTERM); err != nil {
+		if err == syscall.ESRCH {
+			return
+		}
+		log.Printf("Failed to signal remaining processes: %v", err)
+	}
+
+	if r.WaitIdle(timeout) {
+		return
+	}
+
+	log.Printf("Processes still running after %s, sending SIGKILL", timeout)
+	syscall.Kill(-1, syscall.SIGKILL)
+	r.WaitIdle(timeout)
+}
+
+// stopProcesses stops every running supervised process in reverse start
+// order.
+func stopProcesses(cfg *config.RunConfig, processes []config.ProcessConfig, r *reaper.Reaper, sup *supervisor.Supervisor, hk *hooks.Runner) {
+	for i := len(processes) - 1; i >= 0; i-- {
+		stopProcess(cfg, processes[i], r, sup, hk)
+	}
+}
+
+// stopProcess sends the configured kill signal to a supervised process and
+// waits up to the kill timeout for it and all of its descendants to exit.
+// Whatever is still running after that is sent SIGKILL. The application's
+// preStop hooks run first; their failure does not keep it from stopping.
+func stopProcess(cfg *config.RunConfig, proc config.ProcessConfig, r *reaper.Reaper, sup *supervisor.Supervisor, hk *hooks.Runner) {
+	name := proc.Name
+	status, ok := sup.Status(name)
+	if !ok || status.State != supervisor.StateRunning {
+		return
+	}
+
+	if name == config.AppProcessName {
+		if err := runHooks(cfg, proc, config.HookPreStop, hk); err != nil {
+			log.Printf("Stopping process %s despite failed hook: %v", name, err)
+		}
+	}
+
+	tracked := map[int]string{status.Pid: name}
+	trackDescendants(status.Pid, tracked)
+
+	killSignal := cfg.GetKillSignal()
+	timeout := cfg.GetKillTimeout()
+	log.Printf("Sending %s to process %s, waiting up to %s for it to exit", killSignal, name, timeout)
+	signalProcess(status, killSignal, cfg.GetSignalTarget())
+
+	if waitForTracked(status.Pid, tracked, timeout) {
+		return
+	}
+
+	for pid, comm := range tracked {
+		log.Printf("Process %d (%s) did not exit within %s, force-killing it", pid, comm, timeout)
+		syscall.Kill(pid, syscall.SIGKILL)
+	}
+	waitForTracked(status.Pid, tracked, timeout)
+}
+
+// trackDescendants adds the current descendants of pid to tracked.
+func trackDescendants(pid int, tracked map[int]string) {
+	descendants, err := system.Descendants(pid)
+	if err != nil {
+		log.Printf("Failed to list descendants of process %d: %v", pid, err)
+		return
+	}
+	for _, p := range descendants {
+		tracked[p.Pid] = p.Comm
+	}
+}
+
+// waitForTracked waits until every tracked process is gone or timeout
+// expires, picking up descendants spawned in the meantime. It reports whether
+// all tracked processes exited.
+func waitForTracked(pid int, tracked map[int]string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		trackDescendants(pid, tracked)
+
+		for p := range tracked {
+			if err := syscall.Kill(p, 0); err == syscall.ESRCH {
+				delete(tracked, p)
+			}
+		}
+
+		if len(tracked) == 0 {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
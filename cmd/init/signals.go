@@ -0,0 +1,125 @@
+package main
+
+import (
+	"log"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/hooks"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
+	"github.com/TheRealSibasishBehera/init-go/internal/supervisor"
+)
+
+// signalBufferSize keeps bursts of forwarded signals from being dropped while
+// the dispatcher is busy reaping.
+const signalBufferSize = 32
+
+// dispatchSignals is the PID 1 main loop. Exits of supervised processes lead
+// to a restart or, once a critical process is gone, to shutdown; signals are
+// handled by handleSignal.
+func dispatchSignals(signals <-chan os.Signal, exits chan string, cfg *config.RunConfig, processes []config.ProcessConfig, r *reaper.Reaper, sup *supervisor.Supervisor, hk *hooks.Runner) {
+	restarts := make(chan string, len(processes))
+
+	for {
+		select {
+		case name := <-exits:
+			handleExit(cfg, processes, findProcess(processes, name), r, sup, hk, restarts)
+
+		case name := <-restarts:
+			proc := findProcess(processes, name)
+			if err := startProcess(cfg, proc, r, sup, exits); err != nil {
+				log.Printf("Failed to restart process %s: %v", name, err)
+				sup.FailedToStart(name, err)
+				handleExit(cfg, processes, proc, r, sup, hk, restarts)
+			}
+
+		case sig := <-signals:
+			handleSignal(sig, cfg, processes, r, sup, hk)
+		}
+	}
+}
+
+// handleSignal acts on a signal received by init: SIGTERM and SIGINT stop
+// every process and shut the guest down, and every other catchable signal
+// init does not use itself is forwarded to the application.
+func handleSignal(sig os.Signal, cfg *config.RunConfig, processes []config.ProcessConfig, r *reaper.Reaper, sup *supervisor.Supervisor, hk *hooks.Runner) {
+	switch sig {
+	case syscall.SIGCHLD:
+		// The reaper subscribes to SIGCHLD itself.
+
+	case syscall.SIGTERM, syscall.SIGINT:
+		log.Printf("Received %s, stopping processes...", sig)
+		stopProcesses(cfg, processes, r, sup, hk)
+		shutdown(cfg, r, sup)
+
+	case syscall.SIGURG:
+		// Used internally by the Go runtime for goroutine preemption.
+
+	case syscall.SIGPIPE:
+		// Raised in init when an API client disconnects mid-write; it is
+		// not meant for the application.
+
+	case syscall.SIGWINCH:
+		// With a PTY the kernel signals the application itself once the
+		// new size is set.
+		if console := appConsole.Load(); console != nil {
+			console.Resize()
+		} else if app := sup.App(); app.State == supervisor.StateRunning {
+			signalProcess(app, syscall.SIGWINCH, cfg.GetSignalTarget())
+		}
+
+	default:
+		if app := sup.App(); app.State == supervisor.StateRunning {
+			signalProcess(app, sig.(syscall.Signal), cfg.GetSignalTarget())
+		} else {
+			log.Printf("Received %s with no running application, ignoring", sig)
+		}
+	}
+}
+
+// handleExit applies the restart policy of a supervised process that just
+// exited. Once a critical process is gone for good the guest shuts down.
+func handleExit(cfg *config.RunConfig, processes []config.ProcessConfig, proc config.ProcessConfig, r *reaper.Reaper, sup *supervisor.Supervisor, hk *hooks.Runner, restarts chan<- string) {
+	delay, restart := sup.NextRestart(proc.Name, proc.GetRestart())
+	status, _ := sup.Status(proc.Name)
+
+	if restart {
+		log.Printf("Process %s %s, restarting in %s (restart %d)", proc.Name, status.Exit.Reason, delay, status.Restarts)
+		time.AfterFunc(delay, func() { restarts <- proc.Name })
+		return
+	}
+
+	if !proc.Critical {
+		log.Printf("Process %s %s, not restarting", proc.Name, status.Exit.Reason)
+		return
+	}
+
+	log.Printf("Critical process %s %s, shutting down", proc.Name, status.Exit.Reason)
+	stopProcesses(cfg, processes, r, sup, hk)
+	shutdown(cfg, r, sup)
+}
+
+// signalProcess delivers sig to a supervised process, or to its process
+// group when target is config.SignalTargetGroup.
+func signalProcess(status supervisor.ProcessStatus, sig syscall.Signal, target string) {
+	pid := status.Pid
+	if target == config.SignalTargetGroup {
+		pid = -status.Pid
+	}
+
+	log.Printf("Forwarding %s to %s %s %d", sig, status.Name, target, status.Pid)
+	if err := syscall.Kill(pid, sig); err != nil {
+		log.Printf("Failed to forward %s to PID %d: %v", sig, pid, err)
+	}
+}
+
+func findProcess(processes []config.ProcessConfig, name string) config.ProcessConfig {
+	for _, proc := range processes {
+		if proc.Name == name {
+			return proc
+		}
+	}
+	return config.ProcessConfig{Name: name}
+}
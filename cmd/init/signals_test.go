@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/supervisor"
+)
+
+// startApp starts a stand-in for the application and registers it with a
+// new supervisor. The returned channel yields its exit status.
+func startApp(t *testing.T) (*supervisor.Supervisor, <-chan syscall.WaitStatus) {
+	t.Helper()
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start sleep: %v", err)
+	}
+	t.Cleanup(func() { cmd.Process.Kill() })
+
+	processes := []config.ProcessConfig{{Name: config.AppProcessName, Command: cmd.Args, Critical: true}}
+	sup := supervisor.New(processes)
+	sup.Started(config.AppProcessName, cmd.Args, cmd.Process.Pid)
+
+	exited := make(chan syscall.WaitStatus, 1)
+	go func() {
+		cmd.Wait()
+		exited <- cmd.ProcessState.Sys().(syscall.WaitStatus)
+	}()
+	return sup, exited
+}
+
+func TestHandleSignal_Forwards(t *testing.T) {
+	sup, exited := startApp(t)
+
+	handleSignal(syscall.SIGUSR1, &config.RunConfig{}, nil, nil, sup, nil)
+
+	select {
+	case status := <-exited:
+		if !status.Signaled() || status.Signal() != syscall.SIGUSR1 {
+			t.Errorf("Expected the application to be killed by SIGUSR1, got %v", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected SIGUSR1 to be forwarded to the application")
+	}
+}
+
+func TestHandleSignal_DoesNotForwardSIGPIPE(t *testing.T) {
+	sup, exited := startApp(t)
+
+	handleSignal(syscall.SIGPIPE, &config.RunConfig{}, nil, nil, sup, nil)
+
+	select {
+	case status := <-exited:
+		t.Fatalf("Expected SIGPIPE not to reach the application, it exited with %v", status)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
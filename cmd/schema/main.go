@@ -0,0 +1,21 @@
+// Command schema prints the JSON Schema for run.json, so configs can be
+// checked before they are sent to a guest.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+)
+
+func main() {
+	schema, err := config.Schema()
+	if err != nil {
+		log.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	if _, err := os.Stdout.Write(append(schema, '\n')); err != nil {
+		log.Fatalf("Failed to write schema: %v", err)
+	}
+}
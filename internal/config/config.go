@@ -2,13 +2,76 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net"
 	"os"
+	"sort"
 	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ShutdownReboot   = "reboot"
+	ShutdownPowerOff = "poweroff"
+
+	SignalTargetProcess = "process"
+	SignalTargetGroup   = "group"
+
+	DefaultKillTimeout = 5 * time.Second
+
+	// DefaultShutdownLinger and MaxShutdownLinger bound how long init keeps
+	// the API up after the application exits, see ShutdownLinger.
+	DefaultShutdownLinger = 2 * time.Second
+	MaxShutdownLinger     = 60 * time.Second
+
+	RestartNo        = "no"
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+
+	// AppProcessName is the name under which the main application is
+	// supervised next to the configured processes.
+	AppProcessName = "app"
+
+	DefaultInitialBackoff = 1 * time.Second
+	DefaultMaxBackoff     = 60 * time.Second
+	DefaultResetAfter     = 10 * time.Second
+
+	HookPreStart  = "preStart"
+	HookPostStart = "postStart"
+	HookPreStop   = "preStop"
+
+	HookFailureAbort  = "abort"
+	HookFailureIgnore = "ignore"
+
+	DefaultHookTimeout = 30 * time.Second
+
+	DefaultRescueShell = "/bin/sh"
+
+	// DefaultInterface is the guest network interface addresses are
+	// assigned to unless configured.
+	DefaultInterface = "eth0"
+
+	minIPv4MTU       = 68
+	minIPv6MTU       = 1280
+	maxMTU           = 65535
+	maxInterfaceName = 15
+
+	// DefaultPath is the PATH processes get unless configured, as in
+	// Docker.
+	DefaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
 )
 
 type RunConfig struct {
+	// Version is the run.json format the document was written for, see
+	// CurrentVersion. Older documents are migrated when they are parsed; a
+	// document without one is read as version 2.
+	Version int `json:"version,omitempty"`
+
 	ImageConfig  *ImageConfig      `json:"imageConfig,omitempty"`
 	ExecOverride []string          `json:"execOverride,omitempty"`
 	ExtraEnv     map[string]string `json:"extraEnv,omitempty"`
@@ -21,6 +84,116 @@ type RunConfig struct {
 	RootDevice   string            `json:"rootDevice,omitempty"`
 	EtcResolv    *EtcResolv        `json:"etcResolv,omitempty"`
 	EtcHosts     []EtcHost         `json:"etcHosts,omitempty"`
+	// EntrypointOverride replaces the image's entrypoint, see GetCommand.
+	EntrypointOverride []string `json:"entrypointOverride,omitempty"`
+	// ExpandEnv expands $VAR and ${VAR} in CmdOverride from the
+	// application's environment.
+	ExpandEnv bool `json:"expandEnv,omitempty"`
+	// ShutdownAction is what init does to the guest once the application
+	// exits: "reboot" (the default, which makes Firecracker stop the VM) or
+	// "poweroff".
+	ShutdownAction string `json:"shutdownAction,omitempty"`
+	// ShutdownLinger is how many seconds init waits before ShutdownAction
+	// for the application's final status to be read from /v1/app. It stops
+	// waiting as soon as that happens.
+	ShutdownLinger int `json:"shutdownLinger,omitempty"`
+	// SignalTarget selects whether signals received by init are forwarded to
+	// the application process only ("process", the default) or to its whole
+	// process group ("group").
+	SignalTarget string `json:"signalTarget,omitempty"`
+	// KillSignal is sent to the application when init is asked to stop,
+	// e.g. "SIGINT". Defaults to SIGTERM.
+	KillSignal string `json:"killSignal,omitempty"`
+	// KillTimeout is how many seconds the application and its descendants
+	// get to exit after KillSignal before they are sent SIGKILL.
+	KillTimeout int            `json:"killTimeout,omitempty"`
+	Restart     *RestartConfig `json:"restart,omitempty"`
+	// Processes are supervised alongside the main application, e.g. log
+	// shippers or metrics agents.
+	Processes []ProcessConfig `json:"processes,omitempty"`
+	Hooks     *HooksConfig    `json:"hooks,omitempty"`
+	Rescue    *RescueConfig   `json:"rescue,omitempty"`
+	// Secrets are credentials for the application, see SecretConfig.
+	Secrets []SecretConfig `json:"secrets,omitempty"`
+	// Rlimits are set on init at boot and inherited by every process it
+	// starts.
+	Rlimits []RlimitConfig `json:"rlimits,omitempty"`
+	// Capabilities are granted to the application as ambient capabilities
+	// when it runs as a non-root user, e.g. "CAP_NET_BIND_SERVICE". Root
+	// keeps every capability.
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Files are written at boot before the application starts, see
+	// FileConfig.
+	Files []FileConfig `json:"files,omitempty"`
+	// EtcMode decides how /etc/resolv.conf and /etc/hosts are generated:
+	// "replace" (the default) overwrites the image's files, "merge" adds
+	// the configured entries to them.
+	EtcMode string `json:"etcMode,omitempty"`
+	// Routes are static routes and Rules policy routing rules, applied
+	// after the interfaces are configured. See RouteConfig and RuleConfig.
+	Routes []RouteConfig `json:"routes,omitempty"`
+	Rules  []RuleConfig  `json:"rules,omitempty"`
+	// WaitForNetwork holds the application back until the network is
+	// ready, see NetworkWaitConfig.
+	WaitForNetwork *NetworkWaitConfig `json:"waitForNetwork,omitempty"`
+}
+
+// RescueConfig controls the rescue shell init can start on the console to
+// debug a broken guest. Setting init.rescue=1 on the kernel command line
+// starts it before the application regardless of this configuration.
+type RescueConfig struct {
+	// OnFailure starts the shell when a boot phase fails, before the guest
+	// is shut down.
+	OnFailure bool `json:"onFailure,omitempty"`
+	// Shell is the path of the shell to run. Defaults to /bin/sh.
+	Shell string `json:"shell,omitempty"`
+}
+
+// HooksConfig lists commands run around the application's lifecycle. They
+// run once per boot with the application's user, environment and working
+// directory: preStart before the application is first started, postStart
+// right after, and preStop before it is stopped by init.
+type HooksConfig struct {
+	PreStart  []HookConfig `json:"preStart,omitempty"`
+	PostStart []HookConfig `json:"postStart,omitempty"`
+	PreStop   []HookConfig `json:"preStop,omitempty"`
+}
+
+// HookConfig is a single lifecycle hook. Timeout is in seconds. OnFailure
+// is "abort" (the default), which stops the boot, or "ignore". A failing
+// preStop hook never prevents the application from being stopped.
+type HookConfig struct {
+	Command   []string          `json:"command"`
+	Timeout   int               `json:"timeout,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	OnFailure string            `json:"onFailure,omitempty"`
+}
+
+// ProcessConfig describes a named process supervised by init. Critical
+// processes take the guest down with them once they exit for good.
+type ProcessConfig struct {
+	Name       string            `json:"name"`
+	Command    []string          `json:"command"`
+	Env        map[string]string `json:"env,omitempty"`
+	WorkingDir string            `json:"workingDir,omitempty"`
+	User       string            `json:"user,omitempty"`
+	DependsOn  []string          `json:"dependsOn,omitempty"`
+	Critical   bool              `json:"critical,omitempty"`
+	Restart    *RestartConfig    `json:"restart,omitempty"`
+}
+
+// RestartConfig controls whether the application is relaunched after it
+// exits. Backoff durations are in seconds; MaxRetries of 0 means unlimited,
+// as with Docker's on-failure policy.
+type RestartConfig struct {
+	Policy         string `json:"policy,omitempty"`
+	MaxRetries     int    `json:"maxRetries,omitempty"`
+	InitialBackoff int    `json:"initialBackoff,omitempty"`
+	MaxBackoff     int    `json:"maxBackoff,omitempty"`
+	// ResetAfter is how many seconds a process has to run before an exit
+	// no longer counts towards MaxRetries and the backoff starts over.
+	// Defaults to 10.
+	ResetAfter int `json:"resetAfter,omitempty"`
 }
 
 type ImageConfig struct {
@@ -31,9 +204,20 @@ type ImageConfig struct {
 	User       string   `json:"user,omitempty"`
 }
 
+// IPConfig is an address of a guest network interface. IP keeps the host
+// address along with the prefix length, e.g. 192.168.1.100/24 or
+// fdaa::2/64. Gateway is a plain address in the same subnet and family.
+// Interface defaults to eth0 and MTU to the link's own.
 type IPConfig struct {
-	Gateway *net.IPNet
-	IP      *net.IPNet
+	IP        *net.IPNet
+	Gateway   net.IP
+	Interface string
+	MTU       int
+
+	// invalidIP and invalidGateway keep values that could not be parsed
+	// for validate to report along with every other problem.
+	invalidIP      string
+	invalidGateway string
 }
 
 type Mount struct {
@@ -49,17 +233,30 @@ type EtcHost struct {
 	Description string `json:"description,omitempty"`
 }
 
+const (
+	EtcModeReplace = "replace"
+	EtcModeMerge   = "merge"
+)
+
 type EtcResolv struct {
 	Nameservers []string `json:"nameservers,omitempty"`
 	Search      []string `json:"search,omitempty"`
 	Options     []string `json:"options,omitempty"`
 }
 
+// ipConfigJSON is the JSON form of IPConfig.
+type ipConfigJSON struct {
+	Gateway   string `json:"gateway,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	Interface string `json:"interface,omitempty"`
+	MTU       int    `json:"mtu,omitempty"`
+}
+
 func (ip IPConfig) MarshalJSON() ([]byte, error) {
-	aux := &struct {
-		Gateway string `json:"gateway,omitempty"`
-		IP      string `json:"ip,omitempty"`
-	}{}
+	aux := &ipConfigJSON{
+		Interface: ip.Interface,
+		MTU:       ip.MTU,
+	}
 
 	if ip.Gateway != nil {
 		aux.Gateway = ip.Gateway.String()
@@ -71,51 +268,139 @@ func (ip IPConfig) MarshalJSON() ([]byte, error) {
 	return json.Marshal(aux)
 }
 
+// UnmarshalJSON reports type errors as a FieldError with a path relative to
+// the IPConfig, which decodeConfig completes. Addresses that do not parse
+// are left to validate.
 func (ip *IPConfig) UnmarshalJSON(data []byte) error {
-	type Alias IPConfig
-	aux := &struct {
-		Gateway string `json:"gateway,omitempty"`
-		IP      string `json:"ip,omitempty"`
-		*Alias
-	}{
-		Alias: (*Alias)(ip),
-	}
-
+	aux := &ipConfigJSON{}
 	if err := json.Unmarshal(data, aux); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return FieldError{Path: typeErr.Field, Message: fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)}
+		}
 		return err
 	}
 
 	if aux.Gateway != "" {
-		_, gateway, err := net.ParseCIDR(aux.Gateway)
-		if err != nil {
-			return fmt.Errorf("invalid gateway CIDR %s: %v", aux.Gateway, err)
+		if gateway := net.ParseIP(aux.Gateway); gateway != nil {
+			ip.Gateway = gateway
+		} else {
+			ip.invalidGateway = aux.Gateway
 		}
-		ip.Gateway = gateway
 	}
 
 	if aux.IP != "" {
-		_, ipNet, err := net.ParseCIDR(aux.IP)
-		if err != nil {
-			return fmt.Errorf("invalid IP CIDR %s: %v", aux.IP, err)
+		if addr, ipNet, err := net.ParseCIDR(aux.IP); err == nil {
+			// keep the host address, ParseCIDR masks it off the network
+			ipNet.IP = addr
+			if addr.To4() != nil {
+				ipNet.IP = addr.To4()
+			}
+			ip.IP = ipNet
+		} else {
+			ip.invalidIP = aux.IP
 		}
-		ip.IP = ipNet
 	}
 
+	ip.Interface = aux.Interface
+	ip.MTU = aux.MTU
 	return nil
 }
 
+// GetInterface returns the interface the address is assigned to.
+func (ip IPConfig) GetInterface() string {
+	if ip.Interface != "" {
+		return ip.Interface
+	}
+	return DefaultInterface
+}
+
+// IsIPv6 reports whether the address is an IPv6 address.
+func (ip IPConfig) IsIPv6() bool {
+	return ip.IP != nil && ip.IP.IP.To4() == nil
+}
+
+func (ip IPConfig) validate(path string, p *problems) {
+	if ip.invalidGateway != "" {
+		p.add(fieldPath(path, "gateway"), "invalid address %s", ip.invalidGateway)
+	}
+
+	if ip.invalidIP != "" {
+		p.add(fieldPath(path, "ip"), "invalid CIDR %s", ip.invalidIP)
+	} else if ip.IP == nil {
+		p.add(fieldPath(path, "ip"), "IP address is required")
+	} else if ip.Gateway != nil {
+		switch {
+		case (ip.Gateway.To4() == nil) != ip.IsIPv6():
+			p.add(fieldPath(path, "gateway"), "%s is not in the address family of %s", ip.Gateway, ip.IP)
+		// an IPv6 router advertises a link-local address, which is never
+		// in the subnet of the interface's global address
+		case !ip.IP.Contains(ip.Gateway) && !(ip.Gateway.To4() == nil && ip.Gateway.IsLinkLocalUnicast()):
+			p.add(fieldPath(path, "gateway"), "%s is not in the subnet of %s", ip.Gateway, ip.IP)
+		case ip.Gateway.Equal(ip.IP.IP):
+			p.add(fieldPath(path, "gateway"), "must not be the address itself")
+		}
+	}
+
+	if ip.Interface != "" && !isInterfaceName(ip.Interface) {
+		p.add(fieldPath(path, "interface"), "invalid interface name %s", ip.Interface)
+	}
+
+	minMTU := minIPv4MTU
+	if ip.IsIPv6() {
+		minMTU = minIPv6MTU
+	}
+	if ip.MTU != 0 && (ip.MTU < minMTU || ip.MTU > maxMTU) {
+		p.add(fieldPath(path, "mtu"), "must be between %d and %d", minMTU, maxMTU)
+	}
+}
+
+// isInterfaceName reports whether s can be the name of a network
+// interface.
+func isInterfaceName(s string) bool {
+	if s == "" || len(s) > maxInterfaceName || s == "." || s == ".." {
+		return false
+	}
+	return !strings.ContainsAny(s, "/: \t\n")
+}
+
+// LoadConfig loads a run.json or an OCI runtime spec config.json from
+// path, see ParseConfig.
 func LoadConfig(path string) (*RunConfig, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file %s: %v", path, err)
 	}
 
-	var config RunConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config JSON: %v", err)
+	return ParseConfig(data)
+}
+
+// ParseConfig parses a RunConfig from JSON, rejecting unknown fields. The
+// format is detected from the document: an OCI runtime spec config.json
+// is mapped onto a RunConfig and what cannot be represented is logged.
+func ParseConfig(data []byte) (*RunConfig, error) {
+	config, warnings, err := parseConfig(data, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		log.Printf("Config warning: %s", warning)
 	}
+	return config, nil
+}
 
-	return &config, nil
+// ParseConfigLenient parses a RunConfig like ParseConfig, returning
+// unknown fields as warnings instead of failing.
+func ParseConfigLenient(data []byte) (*RunConfig, []string, error) {
+	return parseConfig(data, false)
+}
+
+func parseConfig(data []byte, strict bool) (*RunConfig, []string, error) {
+	config, warnings, err := decodeConfig(data, strict)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
+	return config, warnings, nil
 }
 
 func LoadConfigOrDefault(path string) *RunConfig {
@@ -129,46 +414,143 @@ func LoadConfigOrDefault(path string) *RunConfig {
 	return config
 }
 
+// GetCommand returns the application's argv following the OCI image spec
+// and docker run: the entrypoint followed by the cmd. A cmdOverride replaces
+// the image's cmd and is still passed to the entrypoint. An
+// entrypointOverride replaces the image's entrypoint and, like
+// docker run --entrypoint, drops the image's cmd as well; [""] clears the
+// entrypoint. An execOverride replaces the whole argv.
 func (c *RunConfig) GetCommand() []string {
+	return c.GetCommandForHome("")
+}
+
+// GetCommandForHome is GetCommand with cmdOverride expanded against the
+// environment the application gets, without its secrets, when its user's
+// home directory is home, see GetProcessEnvironment.
+func (c *RunConfig) GetCommandForHome(home string) []string {
 	if len(c.ExecOverride) > 0 {
 		return c.ExecOverride
 	}
 
-	if c.CmdOverride != "" {
-		return strings.Fields(c.CmdOverride)
+	var entrypoint, cmd []string
+	if c.ImageConfig != nil {
+		entrypoint = c.ImageConfig.Entrypoint
+		cmd = c.ImageConfig.Cmd
 	}
 
-	if c.ImageConfig != nil {
-		if len(c.ImageConfig.Cmd) > 0 {
-			if len(c.ImageConfig.Entrypoint) > 0 {
-				result := make([]string, 0, len(c.ImageConfig.Entrypoint)+len(c.ImageConfig.Cmd))
-				result = append(result, c.ImageConfig.Entrypoint...)
-				result = append(result, c.ImageConfig.Cmd...)
-				return result
-			}
-			return c.ImageConfig.Cmd
+	if len(c.EntrypointOverride) > 0 {
+		entrypoint = c.EntrypointOverride
+		if len(entrypoint) == 1 && entrypoint[0] == "" {
+			entrypoint = nil
 		}
+		cmd = nil
+	}
 
-		if len(c.ImageConfig.Entrypoint) > 0 {
-			return c.ImageConfig.Entrypoint
+	if c.CmdOverride != "" {
+		override, err := c.splitCmdOverride(home)
+		if err != nil {
+			return nil
 		}
+		cmd = override
 	}
 
-	return nil
+	if len(entrypoint) == 0 && len(cmd) == 0 {
+		return nil
+	}
+
+	result := make([]string, 0, len(entrypoint)+len(cmd))
+	result = append(result, entrypoint...)
+	result = append(result, cmd...)
+	return result
+}
+
+// splitCmdOverride parses CmdOverride into words with shell quoting rules,
+// expanding variables from the application's environment with HOME
+// defaulting to home. Secrets are left out so they never end up in argv,
+// which anyone in the guest can read.
+func (c *RunConfig) splitCmdOverride(home string) ([]string, error) {
+	var lookup func(string) (string, bool)
+	if c.ExpandEnv {
+		env := make(map[string]string)
+		for _, kv := range c.GetProcessEnvironment(nil, home) {
+			key, value, _ := strings.Cut(kv, "=")
+			env[key] = value
+		}
+		lookup = func(name string) (string, bool) {
+			value, ok := env[name]
+			return value, ok
+		}
+	}
+	return SplitShellWords(c.CmdOverride, lookup)
 }
 
+// GetEnvironment returns the application's environment without per-process
+// overrides, see GetProcessEnvironment.
 func (c *RunConfig) GetEnvironment() []string {
-	var env []string
+	return c.GetProcessEnvironment(nil, "")
+}
+
+// GetProcessEnvironment returns the environment of a process started by
+// init. Later layers override earlier ones: defaults for PATH, HOME,
+// HOSTNAME and TERM, then imageConfig.env, then extraEnv, then env. Every
+// variable appears once, at the position it was first set. home is the
+// default HOME, "/" if empty.
+func (c *RunConfig) GetProcessEnvironment(env map[string]string, home string) []string {
+	if home == "" {
+		home = "/"
+	}
+	term := "linux"
+	if c.TTY {
+		term = "xterm"
+	}
+
+	defaults := []string{"PATH=" + DefaultPath, "HOME=" + home}
+	if c.Hostname != "" {
+		defaults = append(defaults, "HOSTNAME="+c.Hostname)
+	}
+	defaults = append(defaults, "TERM="+term)
+
+	var image []string
+	if c.ImageConfig != nil {
+		image = c.ImageConfig.Env
+	}
 
-	if c.ImageConfig != nil && len(c.ImageConfig.Env) > 0 {
-		env = append(env, c.ImageConfig.Env...)
+	return MergeEnv(MergeEnv(mergeEnv(defaults, image), c.ExtraEnv), env)
+}
+
+// MergeEnv returns env with the variables in vars set, new ones added in
+// order of their names. Every variable appears once, at the position it was
+// first set.
+func MergeEnv(env []string, vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	for key, value := range c.ExtraEnv {
-		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	pairs := make([]string, 0, len(vars))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+vars[key])
 	}
+	return mergeEnv(env, pairs)
+}
 
-	return env
+// mergeEnv merges KEY=value lists, later lists overriding earlier ones.
+func mergeEnv(layers ...[]string) []string {
+	var merged []string
+	index := make(map[string]int)
+	for _, layer := range layers {
+		for _, kv := range layer {
+			key, _, _ := strings.Cut(kv, "=")
+			if i, ok := index[key]; ok {
+				merged[i] = kv
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, kv)
+		}
+	}
+	return merged
 }
 
 func (c *RunConfig) GetUser() string {
@@ -199,6 +581,274 @@ func (c *RunConfig) GetRootDevice() string {
 	return "/dev/vdb"
 }
 
+func (c *RunConfig) GetShutdownAction() string {
+	if c.ShutdownAction != "" {
+		return c.ShutdownAction
+	}
+
+	return ShutdownReboot
+}
+
+func (c *RunConfig) GetShutdownLinger() time.Duration {
+	if c.ShutdownLinger > 0 {
+		return time.Duration(c.ShutdownLinger) * time.Second
+	}
+
+	return DefaultShutdownLinger
+}
+
+func (c *RunConfig) GetSignalTarget() string {
+	if c.SignalTarget != "" {
+		return c.SignalTarget
+	}
+
+	return SignalTargetProcess
+}
+
+func (c *RunConfig) GetKillSignal() syscall.Signal {
+	if c.KillSignal != "" {
+		if sig, err := ParseSignal(c.KillSignal); err == nil {
+			return sig
+		}
+	}
+
+	return syscall.SIGTERM
+}
+
+func (c *RunConfig) GetKillTimeout() time.Duration {
+	if c.KillTimeout > 0 {
+		return time.Duration(c.KillTimeout) * time.Second
+	}
+
+	return DefaultKillTimeout
+}
+
+func (c *RunConfig) GetRestart() RestartConfig {
+	return ProcessConfig{Restart: c.Restart}.GetRestart()
+}
+
+// GetProcesses returns the main application followed by the configured
+// processes.
+func (c *RunConfig) GetProcesses() []ProcessConfig {
+	processes := make([]ProcessConfig, 0, len(c.Processes)+1)
+	processes = append(processes, ProcessConfig{
+		Name:       AppProcessName,
+		Command:    c.GetCommand(),
+		Env:        c.GetSecretEnvironment(),
+		WorkingDir: c.GetWorkingDir(),
+		User:       c.GetUser(),
+		Critical:   true,
+		Restart:    c.Restart,
+	})
+	for _, p := range c.Processes {
+		if p.WorkingDir == "" {
+			p.WorkingDir = c.GetWorkingDir()
+		}
+		if p.User == "" {
+			p.User = c.GetUser()
+		}
+		processes = append(processes, p)
+	}
+	return processes
+}
+
+// GetStartOrder returns the processes from GetProcesses ordered so that every
+// process comes after the ones it depends on. Processes without ordering
+// constraints keep their configured order, with the main application last
+// so sidecars are up before it starts; shutdown uses the reverse order.
+func (c *RunConfig) GetStartOrder() ([]ProcessConfig, error) {
+	all := c.GetProcesses()
+	// move the main application behind the sidecars
+	processes := make([]ProcessConfig, 0, len(all))
+	processes = append(processes, all[1:]...)
+	processes = append(processes, all[0])
+
+	names := make(map[string]bool, len(processes))
+	for _, p := range processes {
+		names[p.Name] = true
+	}
+	for _, p := range processes {
+		for _, dep := range p.DependsOn {
+			if !names[dep] {
+				return nil, fmt.Errorf("process %s depends on unknown process %s", p.Name, dep)
+			}
+		}
+	}
+
+	// repeatedly take the first process whose dependencies have all started
+	started := make(map[string]bool, len(processes))
+	order := make([]ProcessConfig, 0, len(processes))
+	for len(order) < len(processes) {
+		next := -1
+		for i, p := range processes {
+			if !started[p.Name] && dependenciesStarted(p, started) {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			var blocked []string
+			for _, p := range processes {
+				if !started[p.Name] {
+					blocked = append(blocked, p.Name)
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle among %s", strings.Join(blocked, ", "))
+		}
+
+		started[processes[next].Name] = true
+		order = append(order, processes[next])
+	}
+	return order, nil
+}
+
+func dependenciesStarted(p ProcessConfig, started map[string]bool) bool {
+	for _, dep := range p.DependsOn {
+		if !started[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetRestart returns the restart configuration of the process, defaulting
+// to no restarts.
+func (p ProcessConfig) GetRestart() RestartConfig {
+	if p.Restart == nil {
+		return RestartConfig{Policy: RestartNo}
+	}
+
+	restart := *p.Restart
+	if restart.Policy == "" {
+		restart.Policy = RestartNo
+	}
+	return restart
+}
+
+// Backoff returns how long to wait before restart number attempt (counting
+// from zero), doubling from InitialBackoff up to MaxBackoff.
+func (r RestartConfig) Backoff(attempt int) time.Duration {
+	delay := DefaultInitialBackoff
+	if r.InitialBackoff > 0 {
+		delay = time.Duration(r.InitialBackoff) * time.Second
+	}
+	limit := DefaultMaxBackoff
+	if r.MaxBackoff > 0 {
+		limit = time.Duration(r.MaxBackoff) * time.Second
+	}
+
+	for i := 0; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+// GetResetAfter returns how long a process has to run for its restart count
+// and backoff to be reset.
+func (r RestartConfig) GetResetAfter() time.Duration {
+	if r.ResetAfter > 0 {
+		return time.Duration(r.ResetAfter) * time.Second
+	}
+	return DefaultResetAfter
+}
+
+func (r *RestartConfig) validate(path string, p *problems) {
+	if r == nil {
+		return
+	}
+
+	switch r.Policy {
+	case "", RestartNo, RestartOnFailure, RestartAlways:
+	default:
+		p.add(fieldPath(path, "policy"), "unknown policy %s", r.Policy)
+	}
+	if r.MaxRetries < 0 {
+		p.add(fieldPath(path, "maxRetries"), "must not be negative")
+	}
+	if r.InitialBackoff < 0 {
+		p.add(fieldPath(path, "initialBackoff"), "must not be negative")
+	}
+	if r.MaxBackoff < 0 {
+		p.add(fieldPath(path, "maxBackoff"), "must not be negative")
+	}
+	if r.ResetAfter < 0 {
+		p.add(fieldPath(path, "resetAfter"), "must not be negative")
+	}
+}
+
+// GetRescueShell returns the path of the rescue shell.
+func (c *RunConfig) GetRescueShell() string {
+	if c.Rescue == nil || c.Rescue.Shell == "" {
+		return DefaultRescueShell
+	}
+	return c.Rescue.Shell
+}
+
+// GetHooks returns the hooks configured for phase.
+func (c *RunConfig) GetHooks(phase string) []HookConfig {
+	if c.Hooks == nil {
+		return nil
+	}
+
+	switch phase {
+	case HookPreStart:
+		return c.Hooks.PreStart
+	case HookPostStart:
+		return c.Hooks.PostStart
+	case HookPreStop:
+		return c.Hooks.PreStop
+	}
+	return nil
+}
+
+// GetTimeout returns how long the hook may run before it is killed.
+func (h HookConfig) GetTimeout() time.Duration {
+	if h.Timeout == 0 {
+		return DefaultHookTimeout
+	}
+	return time.Duration(h.Timeout) * time.Second
+}
+
+// GetOnFailure returns the hook's failure policy, defaulting to abort.
+func (h HookConfig) GetOnFailure() string {
+	if h.OnFailure == "" {
+		return HookFailureAbort
+	}
+	return h.OnFailure
+}
+
+func (h HookConfig) validate(path string, p *problems) {
+	if len(h.Command) == 0 {
+		p.add(fieldPath(path, "command"), "is required")
+	}
+	if h.Timeout < 0 {
+		p.add(fieldPath(path, "timeout"), "must not be negative")
+	}
+	switch h.OnFailure {
+	case "", HookFailureAbort, HookFailureIgnore:
+	default:
+		p.add(fieldPath(path, "onFailure"), "unknown failure policy %s", h.OnFailure)
+	}
+}
+
+// ParseSignal resolves a signal name such as "SIGINT" or "INT".
+func ParseSignal(name string) (syscall.Signal, error) {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+
+	sig := unix.SignalNum(name)
+	if sig == 0 {
+		return 0, fmt.Errorf("unknown signal %s", name)
+	}
+	return sig, nil
+}
+
 func (c *RunConfig) GetNameservers() []net.IP {
 	if c.EtcResolv == nil {
 		return nil
@@ -223,52 +873,197 @@ func (c *RunConfig) GetHosts() map[string]net.IP {
 	return hosts
 }
 
-// Validate checks the RunConfig for required fields and valid values.
+// GetEtcMode returns how /etc/resolv.conf and /etc/hosts are generated.
+func (c *RunConfig) GetEtcMode() string {
+	if c.EtcMode == "" {
+		return EtcModeReplace
+	}
+	return c.EtcMode
+}
+
+// GetPrimaryIP returns the first configured address, which the hostname
+// resolves to, or nil if there is none.
+func (c *RunConfig) GetPrimaryIP() net.IP {
+	for _, ipConfig := range c.IPConfigs {
+		if ipConfig.IP != nil {
+			return ipConfig.IP.IP
+		}
+	}
+	return nil
+}
+
+// Validate checks the RunConfig for required fields and valid values. It
+// reports every problem it finds as ValidationErrors.
 func (c *RunConfig) Validate() error {
-	if cmd := c.GetCommand(); len(cmd) == 0 {
-		return fmt.Errorf("no command specified to run")
+	var p problems
+
+	if len(c.EntrypointOverride) > 1 && c.EntrypointOverride[0] == "" {
+		p.add("entrypointOverride[0]", "executable must not be empty")
+	}
+
+	cmdOverrideValid := true
+	if c.CmdOverride != "" {
+		if _, err := c.splitCmdOverride(""); err != nil {
+			p.add("cmdOverride", "%v", err)
+			cmdOverrideValid = false
+		}
 	}
 
+	if cmd := c.GetCommand(); len(cmd) == 0 && cmdOverrideValid {
+		p.add("", "no command specified to run")
+	}
+
+	mtus := make(map[string]int)
 	for i, ipConfig := range c.IPConfigs {
-		if ipConfig.IP == nil {
-			return fmt.Errorf("IP config %d: IP address is required", i)
+		path := indexPath("ipConfigs", i)
+		ipConfig.validate(path, &p)
+
+		// the MTU belongs to the interface, not the address
+		if ipConfig.MTU == 0 {
+			continue
+		}
+		name := ipConfig.GetInterface()
+		if mtu, ok := mtus[name]; ok && mtu != ipConfig.MTU {
+			p.add(fieldPath(path, "mtu"), "conflicts with MTU %d of %s", mtu, name)
+		} else {
+			mtus[name] = ipConfig.MTU
 		}
 	}
 
 	for i, mount := range c.Mounts {
+		path := indexPath("mounts", i)
 		if mount.MountPath == "" {
-			return fmt.Errorf("mount %d: mountPath is required", i)
+			p.add(fieldPath(path, "mountPath"), "is required")
 		}
 		if mount.DevicePath == "" {
-			return fmt.Errorf("mount %d: devicePath is required", i)
+			p.add(fieldPath(path, "devicePath"), "is required")
 		}
 	}
 
 	for i, host := range c.EtcHosts {
+		path := indexPath("etcHosts", i)
 		if host.Host == "" {
-			return fmt.Errorf("etcHosts %d: host is required", i)
+			p.add(fieldPath(path, "host"), "is required")
 		}
 		if net.ParseIP(host.IP) == nil {
-			return fmt.Errorf("etcHosts %d: invalid IP address %s", i, host.IP)
+			p.add(fieldPath(path, "ip"), "invalid IP address %s", host.IP)
 		}
 	}
 
 	if c.EtcResolv != nil {
 		for i, ns := range c.EtcResolv.Nameservers {
 			if net.ParseIP(ns) == nil {
-				return fmt.Errorf("etcResolv nameserver %d: invalid IP address %s", i, ns)
+				p.add(indexPath("etcResolv.nameservers", i), "invalid IP address %s", ns)
+			}
+		}
+		// each is a single word in resolv.conf
+		for i, domain := range c.EtcResolv.Search {
+			if domain == "" || strings.ContainsAny(domain, " \t\n#;") {
+				p.add(indexPath("etcResolv.search", i), "invalid search domain %q", domain)
+			}
+		}
+		for i, option := range c.EtcResolv.Options {
+			if option == "" || strings.ContainsAny(option, " \t\n#;") {
+				p.add(indexPath("etcResolv.options", i), "invalid option %q", option)
 			}
 		}
 	}
 
-	return nil
+	switch c.EtcMode {
+	case "", EtcModeReplace, EtcModeMerge:
+	default:
+		p.add("etcMode", "unknown mode %s", c.EtcMode)
+	}
+
+	switch c.ShutdownAction {
+	case "", ShutdownReboot, ShutdownPowerOff:
+	default:
+		p.add("shutdownAction", "unknown action %s", c.ShutdownAction)
+	}
+
+	if c.ShutdownLinger < 0 {
+		p.add("shutdownLinger", "must not be negative")
+	} else if max := int(MaxShutdownLinger / time.Second); c.ShutdownLinger > max {
+		p.add("shutdownLinger", "must be at most %d", max)
+	}
+
+	switch c.SignalTarget {
+	case "", SignalTargetProcess, SignalTargetGroup:
+	default:
+		p.add("signalTarget", "unknown target %s", c.SignalTarget)
+	}
+
+	if c.KillSignal != "" {
+		if _, err := ParseSignal(c.KillSignal); err != nil {
+			p.add("killSignal", "%v", err)
+		}
+	}
+
+	if c.KillTimeout < 0 {
+		p.add("killTimeout", "must not be negative")
+	}
+
+	c.Restart.validate("restart", &p)
+
+	// the start order can only be checked once every process has a unique
+	// name and known dependencies
+	orderable := true
+	names := map[string]bool{AppProcessName: true}
+	for i, proc := range c.Processes {
+		path := indexPath("processes", i)
+		if proc.Name == "" {
+			p.add(fieldPath(path, "name"), "is required")
+			orderable = false
+		} else if names[proc.Name] {
+			p.add(fieldPath(path, "name"), "duplicate name %s", proc.Name)
+			orderable = false
+		}
+		names[proc.Name] = true
+		if len(proc.Command) == 0 {
+			p.add(fieldPath(path, "command"), "is required")
+		}
+		proc.Restart.validate(fieldPath(path, "restart"), &p)
+	}
+
+	for i, proc := range c.Processes {
+		for j, dep := range proc.DependsOn {
+			if !names[dep] {
+				p.add(indexPath(fieldPath(indexPath("processes", i), "dependsOn"), j), "unknown process %s", dep)
+				orderable = false
+			}
+		}
+	}
+	if orderable {
+		if _, err := c.GetStartOrder(); err != nil {
+			p.add("processes", "%v", err)
+		}
+	}
+
+	for _, phase := range []string{HookPreStart, HookPostStart, HookPreStop} {
+		for i, hook := range c.GetHooks(phase) {
+			hook.validate(indexPath(fieldPath("hooks", phase), i), &p)
+		}
+	}
+
+	c.validateSecrets(&p)
+	c.validateLimits(&p)
+	c.validateFiles(&p)
+	c.validateRoutes(&p)
+	if c.WaitForNetwork != nil {
+		c.WaitForNetwork.validate("waitForNetwork", &p)
+	}
+
+	return p.err()
 }
 
+// ToJSON returns the configuration as a CurrentVersion document with
+// secret values redacted.
 func (c *RunConfig) ToJSON() (string, error) {
-	data, err := json.MarshalIndent(c, "", "  ")
+	current := *c.Redacted()
+	current.Version = CurrentVersion
+	data, err := json.MarshalIndent(&current, "", "  ")
 	if err != nil {
 		return "", err
 	}
 	return string(data), nil
 }
-
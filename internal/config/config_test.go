@@ -2,14 +2,18 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestIPConfig_MarshalJSON(t *testing.T) {
-	jsonData := `{"gateway":"192.168.1.1/24","ip":"192.168.1.10/24"}`
+	jsonData := `{"gateway":"192.168.1.1","ip":"192.168.1.10/24","interface":"eth1","mtu":1400}`
 	
 	var ipConfig IPConfig
 	err := json.Unmarshal([]byte(jsonData), &ipConfig)
@@ -22,27 +26,55 @@ func TestIPConfig_MarshalJSON(t *testing.T) {
 		t.Fatalf("Failed to marshal IPConfig: %v", err)
 	}
 
-	expected := `{"gateway":"192.168.1.0/24","ip":"192.168.1.0/24"}`
-	if string(data) != expected {
-		t.Errorf("Expected %s, got %s", expected, string(data))
+	if string(data) != jsonData {
+		t.Errorf("Expected %s, got %s", jsonData, string(data))
 	}
 }
 
 func TestIPConfig_UnmarshalJSON(t *testing.T) {
-	jsonData := `{"gateway":"192.168.1.1/24","ip":"192.168.1.10/24"}`
-	
-	var ipConfig IPConfig
-	err := json.Unmarshal([]byte(jsonData), &ipConfig)
-	if err != nil {
-		t.Fatalf("Failed to unmarshal IPConfig: %v", err)
+	tests := []struct {
+		name     string
+		jsonData string
+		ip       string
+		gateway  string
+		ipv6     bool
+	}{
+		{
+			name:     "IPv4 keeps the host address",
+			jsonData: `{"gateway":"192.168.1.1","ip":"192.168.1.10/24"}`,
+			ip:       "192.168.1.10/24",
+			gateway:  "192.168.1.1",
+		},
+		{
+			name:     "IPv6",
+			jsonData: `{"gateway":"fdaa:0:1::1","ip":"fdaa:0:1::2/64"}`,
+			ip:       "fdaa:0:1::2/64",
+			gateway:  "fdaa:0:1::1",
+			ipv6:     true,
+		},
 	}
 
-	if ipConfig.IP.String() != "192.168.1.0/24" {
-		t.Errorf("Expected IP 192.168.1.0/24, got %s", ipConfig.IP.String())
-	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ipConfig IPConfig
+			err := json.Unmarshal([]byte(tt.jsonData), &ipConfig)
+			if err != nil {
+				t.Fatalf("Failed to unmarshal IPConfig: %v", err)
+			}
 
-	if ipConfig.Gateway.String() != "192.168.1.0/24" {
-		t.Errorf("Expected Gateway 192.168.1.0/24, got %s", ipConfig.Gateway.String())
+			if ipConfig.IP.String() != tt.ip {
+				t.Errorf("Expected IP %s, got %s", tt.ip, ipConfig.IP.String())
+			}
+			if ipConfig.Gateway.String() != tt.gateway {
+				t.Errorf("Expected Gateway %s, got %s", tt.gateway, ipConfig.Gateway.String())
+			}
+			if ipConfig.IsIPv6() != tt.ipv6 {
+				t.Errorf("Expected IsIPv6 %v, got %v", tt.ipv6, ipConfig.IsIPv6())
+			}
+			if ipConfig.GetInterface() != DefaultInterface {
+				t.Errorf("Expected interface %s, got %s", DefaultInterface, ipConfig.GetInterface())
+			}
+		})
 	}
 }
 
@@ -50,9 +82,52 @@ func TestIPConfig_UnmarshalJSON_InvalidCIDR(t *testing.T) {
 	jsonData := `{"ip":"invalid-cidr"}`
 	
 	var ipConfig IPConfig
-	err := json.Unmarshal([]byte(jsonData), &ipConfig)
-	if err == nil {
-		t.Error("Expected error for invalid CIDR, got nil")
+	if err := json.Unmarshal([]byte(jsonData), &ipConfig); err != nil {
+		t.Fatalf("Expected the address to be left to validation, got %v", err)
+	}
+	var p problems
+	ipConfig.validate("", &p)
+	if err := p.err(); err == nil || err.Error() != "ip: invalid CIDR invalid-cidr" {
+		t.Errorf("Expected invalid CIDR error, got %v", err)
+	}
+}
+
+func TestIPConfig_UnmarshalJSON_InvalidGateway(t *testing.T) {
+	jsonData := `{"ip":"192.168.1.10/24","gateway":"192.168.1.1/24"}`
+
+	var ipConfig IPConfig
+	if err := json.Unmarshal([]byte(jsonData), &ipConfig); err != nil {
+		t.Fatalf("Expected the address to be left to validation, got %v", err)
+	}
+	var p problems
+	ipConfig.validate("", &p)
+	if err := p.err(); err == nil || err.Error() != "gateway: invalid address 192.168.1.1/24" {
+		t.Errorf("Expected invalid gateway error, got %v", err)
+	}
+}
+
+func TestRunConfig_Validate_InvalidAddresses(t *testing.T) {
+	data := `{"version": 3, "imageConfig": {"cmd": ["app"]}, "ipConfigs": [
+		{"ip": "10.0.0.2/24", "gateway": "bad"},
+		{"ip": "10.0.0.300/24"}
+	], "mounts": [{"mountPath": "/data"}]}`
+
+	config, err := ParseConfig([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+
+	var validationErrs ValidationErrors
+	if err := config.Validate(); !errors.As(err, &validationErrs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	expected := ValidationErrors{
+		{Path: "ipConfigs[0].gateway", Message: "invalid address bad"},
+		{Path: "ipConfigs[1].ip", Message: "invalid CIDR 10.0.0.300/24"},
+		{Path: "mounts[0].devicePath", Message: "is required"},
+	}
+	if !reflect.DeepEqual(validationErrs, expected) {
+		t.Errorf("Expected %v, got %v", expected, validationErrs)
 	}
 }
 
@@ -149,7 +224,7 @@ func TestRunConfig_GetCommand(t *testing.T) {
 			expected: []string{"override", "command"},
 		},
 		{
-			name: "CmdOverride when no ExecOverride",
+			name: "CmdOverride is passed to the entrypoint",
 			config: RunConfig{
 				CmdOverride: "cmd override with args",
 				ImageConfig: &ImageConfig{
@@ -157,7 +232,48 @@ func TestRunConfig_GetCommand(t *testing.T) {
 					Cmd:        []string{"cmd"},
 				},
 			},
-			expected: []string{"cmd", "override", "with", "args"},
+			expected: []string{"entrypoint", "cmd", "override", "with", "args"},
+		},
+		{
+			name: "CmdOverride with quotes",
+			config: RunConfig{
+				CmdOverride: `sh -c "echo hello world"`,
+			},
+			expected: []string{"sh", "-c", "echo hello world"},
+		},
+		{
+			name: "CmdOverride with expansion",
+			config: RunConfig{
+				CmdOverride: "server --port=${PORT} $MODE",
+				ExpandEnv:   true,
+				ImageConfig: &ImageConfig{Env: []string{"PORT=8080", "MODE=image"}},
+				ExtraEnv:    map[string]string{"MODE": "prod"},
+			},
+			expected: []string{"server", "--port=8080", "prod"},
+		},
+		{
+			name: "CmdOverride without expansion",
+			config: RunConfig{
+				CmdOverride: "echo $HOME",
+				ExtraEnv:    map[string]string{"HOME": "/root"},
+			},
+			expected: []string{"echo", "$HOME"},
+		},
+		{
+			name: "CmdOverride does not expand secrets",
+			config: RunConfig{
+				CmdOverride: "login --token=$TOKEN",
+				ExpandEnv:   true,
+				Secrets:     []SecretConfig{{Name: "token", Value: "s3cr3t", Env: "TOKEN"}},
+			},
+			expected: []string{"login", "--token="},
+		},
+		{
+			name: "Malformed CmdOverride",
+			config: RunConfig{
+				CmdOverride: `sh -c "echo`,
+			},
+			expected: nil,
 		},
 		{
 			name: "Entrypoint + Cmd from ImageConfig",
@@ -211,6 +327,156 @@ func TestRunConfig_GetCommand(t *testing.T) {
 	}
 }
 
+func TestRunConfig_GetCommandForHome(t *testing.T) {
+	config := RunConfig{
+		CmdOverride:  "echo $HOME",
+		ExpandEnv:    true,
+		UserOverride: "nobody",
+	}
+	if result := config.GetCommandForHome("/nonexistent"); !reflect.DeepEqual(result, []string{"echo", "/nonexistent"}) {
+		t.Errorf("Expected HOME to be the user's home, got %v", result)
+	}
+
+	// an explicit HOME wins over the user's home, as it does for the app
+	config.ExtraEnv = map[string]string{"HOME": "/srv"}
+	if result := config.GetCommandForHome("/nonexistent"); !reflect.DeepEqual(result, []string{"echo", "/srv"}) {
+		t.Errorf("Expected the configured HOME, got %v", result)
+	}
+}
+
+// TestRunConfig_GetCommand_DockerConformance mirrors what docker run
+// executes for the same image and flags, with entrypointOverride standing in
+// for --entrypoint and cmdOverride for the arguments after the image name.
+func TestRunConfig_GetCommand_DockerConformance(t *testing.T) {
+	images := map[string]*ImageConfig{
+		"entrypoint+cmd": {
+			Entrypoint: []string{"/docker-entrypoint.sh"},
+			Cmd:        []string{"nginx", "-g", "daemon off;"},
+		},
+		"entrypoint": {
+			Entrypoint: []string{"/bin/app", "--serve"},
+		},
+		"cmd": {
+			Cmd: []string{"python", "app.py"},
+		},
+		"shell-entrypoint": {
+			Entrypoint: []string{"/bin/sh", "-c", "exec /bin/app \"$@\"", "--"},
+			Cmd:        []string{"--port", "80"},
+		},
+		"empty": {},
+	}
+
+	tests := []struct {
+		run        string
+		image      string
+		entrypoint []string
+		cmd        string
+		expected   []string
+	}{
+		{
+			run:      "docker run entrypoint+cmd",
+			image:    "entrypoint+cmd",
+			expected: []string{"/docker-entrypoint.sh", "nginx", "-g", "daemon off;"},
+		},
+		{
+			run:      "docker run entrypoint+cmd nginx -T",
+			image:    "entrypoint+cmd",
+			cmd:      "nginx -T",
+			expected: []string{"/docker-entrypoint.sh", "nginx", "-T"},
+		},
+		{
+			run:        "docker run --entrypoint /bin/sh entrypoint+cmd",
+			image:      "entrypoint+cmd",
+			entrypoint: []string{"/bin/sh"},
+			expected:   []string{"/bin/sh"},
+		},
+		{
+			run:        "docker run --entrypoint /bin/sh entrypoint+cmd -c 'echo hi'",
+			image:      "entrypoint+cmd",
+			entrypoint: []string{"/bin/sh"},
+			cmd:        "-c 'echo hi'",
+			expected:   []string{"/bin/sh", "-c", "echo hi"},
+		},
+		{
+			run:        `docker run --entrypoint "" entrypoint+cmd`,
+			image:      "entrypoint+cmd",
+			entrypoint: []string{""},
+			expected:   nil,
+		},
+		{
+			run:        `docker run --entrypoint "" entrypoint+cmd nginx -v`,
+			image:      "entrypoint+cmd",
+			entrypoint: []string{""},
+			cmd:        "nginx -v",
+			expected:   []string{"nginx", "-v"},
+		},
+		{
+			run:      "docker run entrypoint",
+			image:    "entrypoint",
+			expected: []string{"/bin/app", "--serve"},
+		},
+		{
+			run:      "docker run entrypoint --port 80",
+			image:    "entrypoint",
+			cmd:      "--port 80",
+			expected: []string{"/bin/app", "--serve", "--port", "80"},
+		},
+		{
+			run:      "docker run cmd",
+			image:    "cmd",
+			expected: []string{"python", "app.py"},
+		},
+		{
+			run:      "docker run cmd python -m http.server",
+			image:    "cmd",
+			cmd:      "python -m http.server",
+			expected: []string{"python", "-m", "http.server"},
+		},
+		{
+			run:        "docker run --entrypoint env cmd",
+			image:      "cmd",
+			entrypoint: []string{"env"},
+			expected:   []string{"env"},
+		},
+		{
+			run:      "docker run shell-entrypoint",
+			image:    "shell-entrypoint",
+			expected: []string{"/bin/sh", "-c", "exec /bin/app \"$@\"", "--", "--port", "80"},
+		},
+		{
+			run:      "docker run shell-entrypoint --port 8080",
+			image:    "shell-entrypoint",
+			cmd:      "--port 8080",
+			expected: []string{"/bin/sh", "-c", "exec /bin/app \"$@\"", "--", "--port", "8080"},
+		},
+		{
+			run:      "docker run empty",
+			image:    "empty",
+			expected: nil,
+		},
+		{
+			run:      "docker run empty /bin/true",
+			image:    "empty",
+			cmd:      "/bin/true",
+			expected: []string{"/bin/true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.run, func(t *testing.T) {
+			config := RunConfig{
+				ImageConfig:        images[tt.image],
+				EntrypointOverride: tt.entrypoint,
+				CmdOverride:        tt.cmd,
+			}
+			result := config.GetCommand()
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
 func TestRunConfig_GetEnvironment(t *testing.T) {
 	config := RunConfig{
 		ImageConfig: &ImageConfig{
@@ -224,21 +490,69 @@ func TestRunConfig_GetEnvironment(t *testing.T) {
 
 	env := config.GetEnvironment()
 	
-	if len(env) != 4 {
-		t.Errorf("Expected 4 environment variables, got %d", len(env))
+	expected := []string{"PATH=/bin", "HOME=/root", "TERM=linux", "CUSTOM=value", "DEBUG=true"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v, got %v", expected, env)
 	}
+}
 
-	envMap := make(map[string]bool)
-	for _, e := range env {
-		envMap[e] = true
+func TestRunConfig_GetProcessEnvironment(t *testing.T) {
+	config := RunConfig{
+		Hostname: "web-1",
+		TTY:      true,
+		ImageConfig: &ImageConfig{
+			Env: []string{"LANG=C.UTF-8", "MODE=image", "DEBUG=false", "MODE=image-again"},
+		},
+		ExtraEnv: map[string]string{
+			"MODE":  "extra",
+			"DEBUG": "true",
+			"ZONE":  "ams",
+		},
 	}
 
-	expected := []string{"PATH=/bin", "HOME=/root", "CUSTOM=value", "DEBUG=true"}
-	for _, exp := range expected {
-		if !envMap[exp] {
-			t.Errorf("Expected environment variable %s not found", exp)
+	env := config.GetProcessEnvironment(map[string]string{"MODE": "process", "HOME": "/srv"}, "/home/app")
+
+	expected := []string{
+		"PATH=" + DefaultPath,
+		"HOME=/srv",
+		"HOSTNAME=web-1",
+		"TERM=xterm",
+		"LANG=C.UTF-8",
+		"MODE=process",
+		"DEBUG=true",
+		"ZONE=ams",
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v, got %v", expected, env)
+	}
+
+	// the result must not depend on map iteration order
+	for i := 0; i < 20; i++ {
+		if again := config.GetProcessEnvironment(map[string]string{"MODE": "process", "HOME": "/srv"}, "/home/app"); !reflect.DeepEqual(again, env) {
+			t.Fatalf("Expected a stable environment, got %v and %v", env, again)
 		}
 	}
+
+	defaults := (&RunConfig{}).GetProcessEnvironment(nil, "/home/app")
+	expected = []string{"PATH=" + DefaultPath, "HOME=/home/app", "TERM=linux"}
+	if !reflect.DeepEqual(defaults, expected) {
+		t.Errorf("Expected %v, got %v", expected, defaults)
+	}
+}
+
+
+func TestMergeEnv(t *testing.T) {
+	base := []string{"PATH=/bin", "HOME=/", "PATH=/usr/bin"}
+
+	env := MergeEnv(base, map[string]string{"HOME": "/srv", "B": "2", "A": "1"})
+
+	expected := []string{"PATH=/usr/bin", "HOME=/srv", "A=1", "B=2"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v, got %v", expected, env)
+	}
+	if base[1] != "HOME=/" {
+		t.Errorf("Expected the base environment to be left alone, got %v", base)
+	}
 }
 
 func TestRunConfig_GetUser(t *testing.T) {
@@ -345,6 +659,262 @@ func TestRunConfig_GetRootDevice(t *testing.T) {
 	}
 }
 
+func TestRunConfig_GetShutdownAction(t *testing.T) {
+	config := RunConfig{}
+	if action := config.GetShutdownAction(); action != ShutdownReboot {
+		t.Errorf("Expected default action %s, got %s", ShutdownReboot, action)
+	}
+
+	config.ShutdownAction = ShutdownPowerOff
+	if action := config.GetShutdownAction(); action != ShutdownPowerOff {
+		t.Errorf("Expected action %s, got %s", ShutdownPowerOff, action)
+	}
+}
+
+func TestRunConfig_GetSignalTarget(t *testing.T) {
+	config := RunConfig{}
+	if target := config.GetSignalTarget(); target != SignalTargetProcess {
+		t.Errorf("Expected default target %s, got %s", SignalTargetProcess, target)
+	}
+
+	config.SignalTarget = SignalTargetGroup
+	if target := config.GetSignalTarget(); target != SignalTargetGroup {
+		t.Errorf("Expected target %s, got %s", SignalTargetGroup, target)
+	}
+}
+
+func TestRunConfig_GetKillSignal(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   RunConfig
+		expected syscall.Signal
+	}{
+		{
+			name:     "Default to SIGTERM",
+			config:   RunConfig{},
+			expected: syscall.SIGTERM,
+		},
+		{
+			name:     "Full signal name",
+			config:   RunConfig{KillSignal: "SIGINT"},
+			expected: syscall.SIGINT,
+		},
+		{
+			name:     "Short lowercase signal name",
+			config:   RunConfig{KillSignal: "quit"},
+			expected: syscall.SIGQUIT,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.config.GetKillSignal()
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRunConfig_GetKillTimeout(t *testing.T) {
+	config := RunConfig{}
+	if timeout := config.GetKillTimeout(); timeout != DefaultKillTimeout {
+		t.Errorf("Expected default timeout %s, got %s", DefaultKillTimeout, timeout)
+	}
+
+	config.KillTimeout = 30
+	if timeout := config.GetKillTimeout(); timeout != 30*time.Second {
+		t.Errorf("Expected timeout 30s, got %s", timeout)
+	}
+}
+
+func TestRunConfig_GetShutdownLinger(t *testing.T) {
+	config := RunConfig{}
+	if linger := config.GetShutdownLinger(); linger != DefaultShutdownLinger {
+		t.Errorf("Expected default linger %s, got %s", DefaultShutdownLinger, linger)
+	}
+
+	config.ShutdownLinger = 10
+	if linger := config.GetShutdownLinger(); linger != 10*time.Second {
+		t.Errorf("Expected linger 10s, got %s", linger)
+	}
+}
+
+func TestRunConfig_GetRestart(t *testing.T) {
+	config := RunConfig{}
+	if policy := config.GetRestart().Policy; policy != RestartNo {
+		t.Errorf("Expected default policy %s, got %s", RestartNo, policy)
+	}
+
+	config.Restart = &RestartConfig{MaxRetries: 3}
+	if policy := config.GetRestart().Policy; policy != RestartNo {
+		t.Errorf("Expected empty policy to default to %s, got %s", RestartNo, policy)
+	}
+}
+
+func TestRunConfig_GetHooks(t *testing.T) {
+	config := RunConfig{}
+	if hooks := config.GetHooks(HookPreStart); hooks != nil {
+		t.Errorf("Expected no hooks without a hooks section, got %v", hooks)
+	}
+
+	config.Hooks = &HooksConfig{
+		PreStart:  []HookConfig{{Command: []string{"migrate"}}},
+		PostStart: []HookConfig{{Command: []string{"warm"}}},
+		PreStop:   []HookConfig{{Command: []string{"deregister"}}},
+	}
+	for phase, want := range map[string]string{
+		HookPreStart:  "migrate",
+		HookPostStart: "warm",
+		HookPreStop:   "deregister",
+	} {
+		hooks := config.GetHooks(phase)
+		if len(hooks) != 1 || hooks[0].Command[0] != want {
+			t.Errorf("Expected %s hook %s, got %v", phase, want, hooks)
+		}
+	}
+}
+
+func TestRunConfig_GetRescueShell(t *testing.T) {
+	config := RunConfig{}
+	if shell := config.GetRescueShell(); shell != DefaultRescueShell {
+		t.Errorf("Expected default shell %s, got %s", DefaultRescueShell, shell)
+	}
+
+	config.Rescue = &RescueConfig{OnFailure: true}
+	if shell := config.GetRescueShell(); shell != DefaultRescueShell {
+		t.Errorf("Expected default shell %s, got %s", DefaultRescueShell, shell)
+	}
+
+	config.Rescue.Shell = "/bin/bash"
+	if shell := config.GetRescueShell(); shell != "/bin/bash" {
+		t.Errorf("Expected shell /bin/bash, got %s", shell)
+	}
+}
+
+func TestHookConfig_Defaults(t *testing.T) {
+	hook := HookConfig{Command: []string{"migrate"}}
+	if timeout := hook.GetTimeout(); timeout != DefaultHookTimeout {
+		t.Errorf("Expected default timeout %s, got %s", DefaultHookTimeout, timeout)
+	}
+	if policy := hook.GetOnFailure(); policy != HookFailureAbort {
+		t.Errorf("Expected default failure policy %s, got %s", HookFailureAbort, policy)
+	}
+
+	hook.Timeout = 90
+	hook.OnFailure = HookFailureIgnore
+	if timeout := hook.GetTimeout(); timeout != 90*time.Second {
+		t.Errorf("Expected timeout 90s, got %s", timeout)
+	}
+	if policy := hook.GetOnFailure(); policy != HookFailureIgnore {
+		t.Errorf("Expected failure policy %s, got %s", HookFailureIgnore, policy)
+	}
+}
+
+func TestRestartConfig_Backoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		restart  RestartConfig
+		attempt  int
+		expected time.Duration
+	}{
+		{
+			name:     "Default initial backoff",
+			restart:  RestartConfig{},
+			attempt:  0,
+			expected: DefaultInitialBackoff,
+		},
+		{
+			name:     "Doubles per attempt",
+			restart:  RestartConfig{InitialBackoff: 2},
+			attempt:  3,
+			expected: 16 * time.Second,
+		},
+		{
+			name:     "Capped at max backoff",
+			restart:  RestartConfig{InitialBackoff: 1, MaxBackoff: 10},
+			attempt:  10,
+			expected: 10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.restart.Backoff(tt.attempt)
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestRunConfig_GetProcesses(t *testing.T) {
+	config := RunConfig{
+		ImageConfig: &ImageConfig{
+			Cmd:        []string{"myapp"},
+			WorkingDir: "/app",
+			User:       "app",
+		},
+		Restart: &RestartConfig{Policy: RestartAlways},
+		Processes: []ProcessConfig{
+			{Name: "logs", Command: []string{"vector"}},
+			{Name: "metrics", Command: []string{"agent"}, WorkingDir: "/opt", User: "nobody"},
+		},
+	}
+
+	processes := config.GetProcesses()
+	if len(processes) != 3 {
+		t.Fatalf("Expected 3 processes, got %d", len(processes))
+	}
+
+	app := processes[0]
+	if app.Name != AppProcessName || !app.Critical || app.Command[0] != "myapp" {
+		t.Errorf("Expected critical main application first, got %+v", app)
+	}
+	if app.GetRestart().Policy != RestartAlways {
+		t.Errorf("Expected application to use restart policy %s, got %s", RestartAlways, app.GetRestart().Policy)
+	}
+
+	if processes[1].WorkingDir != "/app" || processes[1].User != "app" {
+		t.Errorf("Expected logs to inherit working dir and user, got %+v", processes[1])
+	}
+	if processes[2].WorkingDir != "/opt" || processes[2].User != "nobody" {
+		t.Errorf("Expected metrics to keep its working dir and user, got %+v", processes[2])
+	}
+}
+
+func TestRunConfig_GetStartOrder(t *testing.T) {
+	config := RunConfig{
+		ImageConfig: &ImageConfig{Cmd: []string{"myapp"}},
+		Processes: []ProcessConfig{
+			{Name: "warmup", Command: []string{"warm"}, DependsOn: []string{AppProcessName}},
+			{Name: "proxy", Command: []string{"envoy"}, DependsOn: []string{"logs"}},
+			{Name: "logs", Command: []string{"vector"}},
+		},
+	}
+
+	order, err := config.GetStartOrder()
+	if err != nil {
+		t.Fatalf("GetStartOrder failed: %v", err)
+	}
+
+	var names []string
+	for _, p := range order {
+		names = append(names, p.Name)
+	}
+
+	expected := []string{"logs", "proxy", AppProcessName, "warmup"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i, name := range names {
+		if name != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, names)
+			break
+		}
+	}
+}
+
 func TestRunConfig_GetNameservers(t *testing.T) {
 	config := RunConfig{
 		EtcResolv: &EtcResolv{
@@ -390,6 +960,21 @@ func TestRunConfig_GetHosts(t *testing.T) {
 	}
 }
 
+func TestRunConfig_GetPrimaryIP(t *testing.T) {
+	config := RunConfig{}
+	if ip := config.GetPrimaryIP(); ip != nil {
+		t.Errorf("Expected no primary IP, got %s", ip)
+	}
+
+	config.IPConfigs = []IPConfig{
+		{IP: mustParseCIDR("fdaa::2/64")},
+		{IP: mustParseCIDR("172.19.0.2/24")},
+	}
+	if ip := config.GetPrimaryIP(); ip.String() != "fdaa::2" {
+		t.Errorf("Expected primary IP fdaa::2, got %s", ip)
+	}
+}
+
 func TestRunConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -431,7 +1016,85 @@ func TestRunConfig_Validate(t *testing.T) {
 				IPConfigs:   []IPConfig{{}},
 			},
 			expectError: true,
-			errorMsg:    "IP config 0: IP address is required",
+			errorMsg:    "ipConfigs[0].ip: IP address is required",
+		},
+		{
+			name: "IP config gateway outside the subnet",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				IPConfigs: []IPConfig{
+					{IP: mustParseCIDR("192.168.1.10/24"), Gateway: net.ParseIP("192.168.1.1")},
+					{IP: mustParseCIDR("192.168.1.10/24"), Gateway: net.ParseIP("192.168.2.1")},
+				},
+			},
+			expectError: true,
+			errorMsg:    "ipConfigs[1].gateway: 192.168.2.1 is not in the subnet of 192.168.1.10/24",
+		},
+		{
+			name: "IP config with a link-local IPv6 gateway",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				IPConfigs: []IPConfig{
+					{IP: mustParseCIDR("fdaa::2/64"), Gateway: net.ParseIP("fe80::1")},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "IP config with a link-local IPv4 gateway outside the subnet",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				IPConfigs: []IPConfig{
+					{IP: mustParseCIDR("10.0.0.2/24"), Gateway: net.ParseIP("169.254.0.1")},
+				},
+			},
+			expectError: true,
+			errorMsg:    "ipConfigs[0].gateway: 169.254.0.1 is not in the subnet of 10.0.0.2/24",
+		},
+		{
+			name: "IP config gateway of another family",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				IPConfigs: []IPConfig{
+					{IP: mustParseCIDR("fdaa::2/64"), Gateway: net.ParseIP("192.168.1.1")},
+				},
+			},
+			expectError: true,
+			errorMsg:    "ipConfigs[0].gateway: 192.168.1.1 is not in the address family of fdaa::2/64",
+		},
+		{
+			name: "IP config gateway is the address",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				IPConfigs: []IPConfig{
+					{IP: mustParseCIDR("192.168.1.10/24"), Gateway: net.ParseIP("192.168.1.10")},
+				},
+			},
+			expectError: true,
+			errorMsg:    "ipConfigs[0].gateway: must not be the address itself",
+		},
+		{
+			name: "IP config invalid interface and MTU",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				IPConfigs: []IPConfig{
+					{IP: mustParseCIDR("fdaa::2/64"), Interface: "a-very-long-interface", MTU: 1000},
+				},
+			},
+			expectError: true,
+			errorMsg:    "ipConfigs[0].interface: invalid interface name a-very-long-interface; ipConfigs[0].mtu: must be between 1280 and 65535",
+		},
+		{
+			name: "IP config conflicting MTU",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				IPConfigs: []IPConfig{
+					{IP: mustParseCIDR("192.168.1.10/24"), MTU: 1500},
+					{IP: mustParseCIDR("fdaa::2/64"), Interface: "eth0", MTU: 9000},
+				},
+			},
+			expectError: true,
+			errorMsg:    "ipConfigs[1].mtu: conflicts with MTU 1500 of eth0",
 		},
 		{
 			name: "Mount missing mountPath",
@@ -440,7 +1103,7 @@ func TestRunConfig_Validate(t *testing.T) {
 				Mounts:      []Mount{{DevicePath: "/dev/sdb"}},
 			},
 			expectError: true,
-			errorMsg:    "mount 0: mountPath is required",
+			errorMsg:    "mounts[0].mountPath: is required",
 		},
 		{
 			name: "Mount missing devicePath",
@@ -449,7 +1112,7 @@ func TestRunConfig_Validate(t *testing.T) {
 				Mounts:      []Mount{{MountPath: "/data"}},
 			},
 			expectError: true,
-			errorMsg:    "mount 0: devicePath is required",
+			errorMsg:    "mounts[0].devicePath: is required",
 		},
 		{
 			name: "EtcHost missing host",
@@ -458,7 +1121,7 @@ func TestRunConfig_Validate(t *testing.T) {
 				EtcHosts:    []EtcHost{{IP: "127.0.0.1"}},
 			},
 			expectError: true,
-			errorMsg:    "etcHosts 0: host is required",
+			errorMsg:    "etcHosts[0].host: is required",
 		},
 		{
 			name: "EtcHost invalid IP",
@@ -467,7 +1130,204 @@ func TestRunConfig_Validate(t *testing.T) {
 				EtcHosts:    []EtcHost{{Host: "localhost", IP: "invalid"}},
 			},
 			expectError: true,
-			errorMsg:    "etcHosts 0: invalid IP address invalid",
+			errorMsg:    "etcHosts[0].ip: invalid IP address invalid",
+		},
+		{
+			name: "Unknown shutdown action",
+			config: RunConfig{
+				ImageConfig:    &ImageConfig{Cmd: []string{"echo"}},
+				ShutdownAction: "halt",
+			},
+			expectError: true,
+			errorMsg:    "shutdownAction: unknown action halt",
+		},
+		{
+			name: "Unknown signal target",
+			config: RunConfig{
+				ImageConfig:  &ImageConfig{Cmd: []string{"echo"}},
+				SignalTarget: "session",
+			},
+			expectError: true,
+			errorMsg:    "signalTarget: unknown target session",
+		},
+		{
+			name: "Unknown kill signal",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				KillSignal:  "SIGNOPE",
+			},
+			expectError: true,
+			errorMsg:    "killSignal: unknown signal SIGNOPE",
+		},
+		{
+			name: "Negative kill timeout",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				KillTimeout: -1,
+			},
+			expectError: true,
+			errorMsg:    "killTimeout: must not be negative",
+		},
+		{
+			name: "Negative shutdown linger",
+			config: RunConfig{
+				ImageConfig:    &ImageConfig{Cmd: []string{"echo"}},
+				ShutdownLinger: -1,
+			},
+			expectError: true,
+			errorMsg:    "shutdownLinger: must not be negative",
+		},
+		{
+			name: "Shutdown linger too long",
+			config: RunConfig{
+				ImageConfig:    &ImageConfig{Cmd: []string{"echo"}},
+				ShutdownLinger: 3600,
+			},
+			expectError: true,
+			errorMsg:    "shutdownLinger: must be at most 60",
+		},
+		{
+			name: "Negative restart reset window",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Restart:     &RestartConfig{Policy: RestartAlways, ResetAfter: -1},
+			},
+			expectError: true,
+			errorMsg:    "restart.resetAfter: must not be negative",
+		},
+		{
+			name: "Unknown restart policy",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Restart:     &RestartConfig{Policy: "unless-stopped"},
+			},
+			expectError: true,
+			errorMsg:    "restart.policy: unknown policy unless-stopped",
+		},
+		{
+			name: "Negative restart retries",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Restart:     &RestartConfig{Policy: RestartAlways, MaxRetries: -1},
+			},
+			expectError: true,
+			errorMsg:    "restart.maxRetries: must not be negative",
+		},
+		{
+			name: "Process missing name",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Processes:   []ProcessConfig{{Command: []string{"vector"}}},
+			},
+			expectError: true,
+			errorMsg:    "processes[0].name: is required",
+		},
+		{
+			name: "Process with reserved name",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Processes:   []ProcessConfig{{Name: "app", Command: []string{"vector"}}},
+			},
+			expectError: true,
+			errorMsg:    "processes[0].name: duplicate name app",
+		},
+		{
+			name: "Process missing command",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Processes:   []ProcessConfig{{Name: "logs"}},
+			},
+			expectError: true,
+			errorMsg:    "processes[0].command: is required",
+		},
+		{
+			name: "Process depends on unknown process",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Processes: []ProcessConfig{
+					{Name: "logs", Command: []string{"vector"}, DependsOn: []string{"db"}},
+				},
+			},
+			expectError: true,
+			errorMsg:    "processes[0].dependsOn[0]: unknown process db",
+		},
+		{
+			name: "Process dependency cycle",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Processes: []ProcessConfig{
+					{Name: "a", Command: []string{"a"}, DependsOn: []string{"b"}},
+					{Name: "b", Command: []string{"b"}, DependsOn: []string{"a"}},
+				},
+			},
+			expectError: true,
+			errorMsg:    "processes: dependency cycle among a, b",
+		},
+		{
+			name: "Cleared entrypoint without cmd",
+			config: RunConfig{
+				ImageConfig:        &ImageConfig{Entrypoint: []string{"/entrypoint.sh"}, Cmd: []string{"serve"}},
+				EntrypointOverride: []string{""},
+			},
+			expectError: true,
+			errorMsg:    "no command specified to run",
+		},
+		{
+			name: "Entrypoint override starting with an empty argument",
+			config: RunConfig{
+				EntrypointOverride: []string{"", "serve"},
+			},
+			expectError: true,
+			errorMsg:    "entrypointOverride[0]: executable must not be empty",
+		},
+		{
+			name: "Malformed cmdOverride",
+			config: RunConfig{
+				CmdOverride: `sh -c "echo hello`,
+			},
+			expectError: true,
+			errorMsg:    "cmdOverride: unterminated double quote at position 7",
+		},
+		{
+			name: "Valid hooks",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Hooks: &HooksConfig{
+					PreStart: []HookConfig{{Command: []string{"migrate"}, Timeout: 120}},
+					PreStop:  []HookConfig{{Command: []string{"deregister"}, OnFailure: HookFailureIgnore}},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Hook missing command",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Hooks:       &HooksConfig{PostStart: []HookConfig{{Timeout: 5}}},
+			},
+			expectError: true,
+			errorMsg:    "hooks.postStart[0].command: is required",
+		},
+		{
+			name: "Hook negative timeout",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Hooks:       &HooksConfig{PreStart: []HookConfig{{Command: []string{"migrate"}, Timeout: -1}}},
+			},
+			expectError: true,
+			errorMsg:    "hooks.preStart[0].timeout: must not be negative",
+		},
+		{
+			name: "Hook unknown failure policy",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				Hooks: &HooksConfig{PreStop: []HookConfig{
+					{Command: []string{"deregister"}},
+					{Command: []string{"flush"}, OnFailure: "retry"},
+				}},
+			},
+			expectError: true,
+			errorMsg:    "hooks.preStop[1].onFailure: unknown failure policy retry",
 		},
 		{
 			name: "EtcResolv invalid nameserver",
@@ -478,7 +1338,28 @@ func TestRunConfig_Validate(t *testing.T) {
 				},
 			},
 			expectError: true,
-			errorMsg:    "etcResolv nameserver 0: invalid IP address invalid-ip",
+			errorMsg:    "etcResolv.nameservers[0]: invalid IP address invalid-ip",
+		},
+		{
+			name: "EtcResolv invalid search domain and option",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				EtcResolv: &EtcResolv{
+					Search:  []string{"internal example.com"},
+					Options: []string{""},
+				},
+			},
+			expectError: true,
+			errorMsg:    `etcResolv.search[0]: invalid search domain "internal example.com"; etcResolv.options[0]: invalid option ""`,
+		},
+		{
+			name: "Unknown etc mode",
+			config: RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+				EtcMode:     "append",
+			},
+			expectError: true,
+			errorMsg:    "etcMode: unknown mode append",
 		},
 	}
 
@@ -500,6 +1381,42 @@ func TestRunConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestRunConfig_Validate_ReportsEveryProblem(t *testing.T) {
+	config := RunConfig{
+		ImageConfig: &ImageConfig{Cmd: []string{"echo"}},
+		Mounts: []Mount{
+			{MountPath: "/data", DevicePath: "/dev/vdb"},
+			{DevicePath: "/dev/vdc"},
+		},
+		EtcHosts:   []EtcHost{{Host: "db", IP: "not-an-ip"}},
+		KillSignal: "SIGNOPE",
+		Processes: []ProcessConfig{
+			{Name: "worker"},
+		},
+	}
+
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("Expected error but got nil")
+	}
+
+	expected := "mounts[1].mountPath: is required; " +
+		"etcHosts[0].ip: invalid IP address not-an-ip; " +
+		"killSignal: unknown signal SIGNOPE; " +
+		"processes[0].command: is required"
+	if err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%s'", expected, err.Error())
+	}
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected ValidationErrors, got %T", err)
+	}
+	if len(errs) != 4 || errs[0].Path != "mounts[1].mountPath" {
+		t.Errorf("Unexpected problems: %#v", errs)
+	}
+}
+
 func TestRunConfig_ToJSON(t *testing.T) {
 	config := RunConfig{
 		Hostname: "test-host",
@@ -526,10 +1443,15 @@ func TestRunConfig_ToJSON(t *testing.T) {
 	}
 }
 
+// mustParseCIDR parses cidr keeping its host address, like IPConfig.
 func mustParseCIDR(cidr string) *net.IPNet {
-	_, ipNet, err := net.ParseCIDR(cidr)
+	ip, ipNet, err := net.ParseCIDR(cidr)
 	if err != nil {
 		panic(err)
 	}
+	if ip.To4() != nil {
+		ip = ip.To4()
+	}
+	ipNet.IP = ip
 	return ipNet
 }
\ No newline at end of file
@@ -0,0 +1,333 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// jsonShadows maps types with custom JSON decoding to the struct that
+// describes their JSON fields.
+var jsonShadows = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(IPConfig{}): reflect.TypeOf(ipConfigJSON{}),
+}
+
+// decodeConfig parses a RunConfig from a run.json or an OCI runtime spec
+// config.json, migrating run.json documents written for an older version
+// first. Keys that do not match a field exactly are rejected if strict is
+// set and returned as warnings otherwise; unsupported OCI settings are
+// always warnings. Syntax and type errors carry their line and column.
+func decodeConfig(data []byte, strict bool) (*RunConfig, []string, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, describeJSONError(data, err)
+	}
+
+	source := data
+	if doc, ok := raw.(map[string]any); ok {
+		if isOCISpec(doc) {
+			return decodeOCI(data, raw)
+		}
+
+		migrated, err := migrate(doc)
+		if err != nil {
+			return nil, nil, err
+		}
+		if migrated {
+			if data, err = json.Marshal(doc); err != nil {
+				return nil, nil, err
+			}
+			// positions in the migrated document would point nowhere in
+			// the one that was written
+			source = nil
+		}
+	}
+
+	var config RunConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, nil, describeJSONError(source, locateFieldError(raw, reflect.TypeOf(config), err))
+	}
+	config.Version = CurrentVersion
+
+	var unknown problems
+	unknownFields(raw, reflect.TypeOf(config), "", &unknown)
+	if len(unknown.errs) == 0 {
+		return &config, nil, nil
+	}
+	if strict {
+		return nil, nil, unknown.errs
+	}
+
+	warnings := make([]string, len(unknown.errs))
+	for i, err := range unknown.errs {
+		warnings[i] = err.Error()
+	}
+	return &config, warnings, nil
+}
+
+// unknownFields reports every key in value that has no field in t.
+func unknownFields(value any, t reflect.Type, path string, p *problems) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if shadow, ok := jsonShadows[t]; ok {
+		t = shadow
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(object) {
+			field, ok := fields[key]
+			if !ok {
+				p.add(fieldPath(path, key), "unknown field%s", suggestField(key, fields))
+				continue
+			}
+			unknownFields(object[key], field.Type, fieldPath(path, key), p)
+		}
+
+	case reflect.Slice, reflect.Array:
+		array, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for i, element := range array {
+			unknownFields(element, t.Elem(), indexPath(path, i), p)
+		}
+
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(object) {
+			unknownFields(object[key], t.Elem(), fieldPath(path, key), p)
+		}
+	}
+}
+
+// locateFieldError completes the path of a FieldError returned by a type
+// with custom JSON decoding, which only knows the path within itself, by
+// finding the value in value that fails to decode.
+func locateFieldError(value any, t reflect.Type, err error) error {
+	var fieldErr FieldError
+	if !errors.As(err, &fieldErr) {
+		return err
+	}
+	path, ok := invalidValue(value, t, "")
+	if !ok {
+		return err
+	}
+	return FieldError{Path: fieldPath(path, fieldErr.Path), Message: fieldErr.Message}
+}
+
+// invalidValue returns the path of the first value of a type with custom
+// JSON decoding in value that fails to decode.
+func invalidValue(value any, t reflect.Type, path string) (string, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if _, ok := jsonShadows[t]; ok {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+		if err := json.Unmarshal(data, reflect.New(t).Interface()); err != nil {
+			return path, true
+		}
+		return "", false
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(object) {
+			if field, ok := fields[key]; ok {
+				if path, ok := invalidValue(object[key], field.Type, fieldPath(path, key)); ok {
+					return path, true
+				}
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		array, ok := value.([]any)
+		if !ok {
+			return "", false
+		}
+		for i, element := range array {
+			if path, ok := invalidValue(element, t.Elem(), indexPath(path, i)); ok {
+				return path, true
+			}
+		}
+
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		for _, key := range sortedKeys(object) {
+			if path, ok := invalidValue(object[key], t.Elem(), fieldPath(path, key)); ok {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// sortedKeys returns the keys of object in order, so that problems are
+// reported in the same order every time.
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonFields returns the fields of t by their JSON name.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// suggestField points out a field that only differs from key in case, which
+// encoding/json would silently accept.
+func suggestField(key string, fields map[string]reflect.StructField) string {
+	for name := range fields {
+		if strings.EqualFold(name, key) {
+			return fmt.Sprintf(" (did you mean %s?)", name)
+		}
+	}
+	return ""
+}
+
+// describeJSONError adds the line and column to JSON syntax and type
+// errors and to FieldErrors. data may be nil if the positions are not
+// meaningful.
+func describeJSONError(data []byte, err error) error {
+	var fieldErr FieldError
+	if errors.As(err, &fieldErr) {
+		offset, ok := valueEnd(data, fieldErr.Path)
+		if !ok {
+			return fieldErr
+		}
+		line, column := position(data, offset)
+		return fmt.Errorf("line %d, column %d: %v", line, column, fieldErr)
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) && data != nil {
+		line, column := position(data, syntaxErr.Offset)
+		return fmt.Errorf("line %d, column %d: %v", line, column, syntaxErr)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		message := fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)
+		if typeErr.Field != "" {
+			message = typeErr.Field + ": " + message
+		}
+		if data == nil {
+			return errors.New(message)
+		}
+		line, column := position(data, typeErr.Offset)
+		return fmt.Errorf("line %d, column %d: %s", line, column, message)
+	}
+
+	return err
+}
+
+// valueEnd returns the offset just past the value at path in data, the
+// position encoding/json reports type errors at.
+func valueEnd(data []byte, path string) (int64, bool) {
+	if data == nil {
+		return 0, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var end int64 = -1
+	var walk func(current string) error
+	walk = func(current string) error {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'):
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if err := walk(fieldPath(current, key.(string))); err != nil || end >= 0 {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(indexPath(current, i)); err != nil || end >= 0 {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+		}
+		if current == path {
+			end = dec.InputOffset()
+		}
+		return nil
+	}
+
+	if err := walk(""); err != nil || end < 0 {
+		return 0, false
+	}
+	return end, true
+}
+
+// position converts the offset encoding/json reports, the number of bytes
+// read when the error occurred, into a 1-based line and column.
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	// the offending byte is the last one read
+	if offset > 0 {
+		offset--
+	}
+
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig_UnknownFields(t *testing.T) {
+	data := `{
+		"hostname": "app",
+		"extraenv": {"A": "1"},
+		"imageConfig": {"cmd": ["/bin/app"], "EntryPoint": []},
+		"ipConfigs": [{"ip": "10.0.0.2/24", "mask": "255.255.255.0"}],
+		"mounts": [
+			{"mountPath": "/data", "devicePath": "/dev/vdb"},
+			{"mountPath": "/logs", "device": "/dev/vdc"}
+		],
+		"hooks": {"preStart": [{"command": ["true"], "retries": 3}]},
+		"processes": [{"name": "worker", "command": ["/bin/worker"], "dependson": ["app"]}]
+	}`
+
+	_, err := ParseConfig([]byte(data))
+	if err == nil {
+		t.Fatal("Expected error but got nil")
+	}
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected ValidationErrors, got %T", err)
+	}
+	expected := ValidationErrors{
+		{Path: "extraenv", Message: "unknown field (did you mean extraEnv?)"},
+		{Path: "hooks.preStart[0].retries", Message: "unknown field"},
+		{Path: "imageConfig.EntryPoint", Message: "unknown field (did you mean entrypoint?)"},
+		{Path: "ipConfigs[0].mask", Message: "unknown field"},
+		{Path: "mounts[1].device", Message: "unknown field"},
+		{Path: "processes[0].dependson", Message: "unknown field (did you mean dependsOn?)"},
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("Expected %v, got %v", expected, errs)
+	}
+}
+
+func TestUnknownFields_MapOrder(t *testing.T) {
+	type entry struct {
+		Name string `json:"name"`
+	}
+	value := map[string]any{}
+	for _, key := range []string{"e", "b", "d", "a", "c"} {
+		value[key] = map[string]any{"Name": key}
+	}
+
+	expected := ValidationErrors{
+		{Path: "a.Name", Message: "unknown field (did you mean name?)"},
+		{Path: "b.Name", Message: "unknown field (did you mean name?)"},
+		{Path: "c.Name", Message: "unknown field (did you mean name?)"},
+		{Path: "d.Name", Message: "unknown field (did you mean name?)"},
+		{Path: "e.Name", Message: "unknown field (did you mean name?)"},
+	}
+	for i := 0; i < 20; i++ {
+		var p problems
+		unknownFields(value, reflect.TypeOf(map[string]entry{}), "", &p)
+		if !reflect.DeepEqual(p.errs, expected) {
+			t.Fatalf("Expected %v, got %v", expected, p.errs)
+		}
+	}
+}
+
+func TestParseConfigLenient(t *testing.T) {
+	data := `{"hostname": "app", "extraenv": {"A": "1"}, "mounts": [{"mountPath": "/data", "device": "/dev/vdb"}]}`
+
+	config, warnings, err := ParseConfigLenient([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseConfigLenient failed: %v", err)
+	}
+	if config.Hostname != "app" {
+		t.Errorf("Expected hostname app, got %s", config.Hostname)
+	}
+
+	expected := []string{
+		"extraenv: unknown field (did you mean extraEnv?)",
+		"mounts[0].device: unknown field",
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected warnings %q, got %q", expected, warnings)
+	}
+}
+
+func TestParseConfig_Positions(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		errorMsg string
+	}{
+		{
+			name:     "Syntax error",
+			data:     "{\n  \"hostname\": \"app\",\n  \"tty\": tru\n}",
+			errorMsg: "failed to parse config JSON: line 3, column 13: invalid character '\\n' in literal true (expecting 'e')",
+		},
+		{
+			name:     "Type error",
+			data:     "{\n  \"hostname\": \"app\",\n  \"killTimeout\": \"10s\"\n}",
+			errorMsg: "failed to parse config JSON: line 3, column 22: killTimeout: cannot use string as int",
+		},
+		{
+			name:     "IP config type error",
+			data:     "{\n  \"version\": 3,\n  \"ipConfigs\": [{\"ip\": \"10.0.0.2/24\", \"mtu\": \"1500\"}]\n}",
+			errorMsg: "failed to parse config JSON: line 3, column 51: ipConfigs[0].mtu: cannot use string as int",
+		},
+		{
+			name:     "Truncated document",
+			data:     "{\n  \"hostname\": \"app\"",
+			errorMsg: "failed to parse config JSON: line 2, column 19: unexpected end of JSON input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.data))
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestPosition(t *testing.T) {
+	data := []byte("ab\ncd\n")
+
+	tests := []struct {
+		offset int64
+		line   int
+		column int
+	}{
+		{offset: 0, line: 1, column: 1},
+		{offset: 1, line: 1, column: 1},
+		{offset: 2, line: 1, column: 2},
+		{offset: 4, line: 2, column: 1},
+		{offset: 100, line: 2, column: 3},
+	}
+
+	for _, tt := range tests {
+		line, column := position(data, tt.offset)
+		if line != tt.line || column != tt.column {
+			t.Errorf("Offset %d: expected %d:%d, got %d:%d", tt.offset, tt.line, tt.column, line, column)
+		}
+	}
+
+	if !strings.Contains(describeJSONError(data, errors.New("boom")).Error(), "boom") {
+		t.Error("Expected other errors to pass through")
+	}
+}
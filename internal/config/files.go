@@ -0,0 +1,95 @@
+package config
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+)
+
+const (
+	FileEncodingPlain  = "plain"
+	FileEncodingBase64 = "base64"
+
+	FileOverwriteReplace = "replace"
+	FileOverwriteSkip    = "skip"
+	FileOverwriteFail    = "fail"
+
+	// DefaultFileMode is the mode of written files unless configured.
+	DefaultFileMode os.FileMode = 0644
+)
+
+// FileConfig is a file init writes at boot, e.g. an nginx snippet or an
+// .env file. Content is taken as is unless Encoding is "base64". Mode is
+// octal and defaults to 0644; Owner is user[:group] and defaults to root.
+// Overwrite decides what happens to an existing file: "replace" (the
+// default), "skip" it or "fail" the boot. Missing parent directories are
+// created.
+type FileConfig struct {
+	Path      string `json:"path"`
+	Content   string `json:"content,omitempty"`
+	Encoding  string `json:"encoding,omitempty"`
+	Mode      string `json:"mode,omitempty"`
+	Owner     string `json:"owner,omitempty"`
+	Overwrite string `json:"overwrite,omitempty"`
+}
+
+// GetContent returns the decoded content of the file.
+func (f FileConfig) GetContent() ([]byte, error) {
+	if f.Encoding == FileEncodingBase64 {
+		return base64.StdEncoding.DecodeString(f.Content)
+	}
+	return []byte(f.Content), nil
+}
+
+// GetMode returns the mode of the file.
+func (f FileConfig) GetMode() os.FileMode {
+	mode, err := parseFileMode(f.Mode)
+	if err != nil || f.Mode == "" {
+		return DefaultFileMode
+	}
+	return mode
+}
+
+// GetOverwrite returns what to do if the file already exists.
+func (f FileConfig) GetOverwrite() string {
+	if f.Overwrite == "" {
+		return FileOverwriteReplace
+	}
+	return f.Overwrite
+}
+
+func (c *RunConfig) validateFiles(p *problems) {
+	paths := make(map[string]bool)
+	for i, file := range c.Files {
+		path := indexPath("files", i)
+		switch {
+		case file.Path == "":
+			p.add(fieldPath(path, "path"), "is required")
+		case !filepath.IsAbs(file.Path) || filepath.Clean(file.Path) != file.Path:
+			p.add(fieldPath(path, "path"), "must be an absolute, clean path, got %s", file.Path)
+		case paths[file.Path]:
+			p.add(fieldPath(path, "path"), "duplicate path %s", file.Path)
+		}
+		paths[file.Path] = true
+
+		switch file.Encoding {
+		case "", FileEncodingPlain:
+		case FileEncodingBase64:
+			if _, err := file.GetContent(); err != nil {
+				p.add(fieldPath(path, "content"), "invalid base64: %v", err)
+			}
+		default:
+			p.add(fieldPath(path, "encoding"), "unknown encoding %s", file.Encoding)
+		}
+
+		if _, err := parseFileMode(file.Mode); err != nil {
+			p.add(fieldPath(path, "mode"), "%v", err)
+		}
+
+		switch file.Overwrite {
+		case "", FileOverwriteReplace, FileOverwriteSkip, FileOverwriteFail:
+		default:
+			p.add(fieldPath(path, "overwrite"), "unknown overwrite policy %s", file.Overwrite)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFileConfig_Defaults(t *testing.T) {
+	file := FileConfig{Path: "/etc/app.conf", Content: "a=b"}
+
+	content, err := file.GetContent()
+	if err != nil || string(content) != "a=b" {
+		t.Errorf("Expected content a=b, got %q (%v)", content, err)
+	}
+	if file.GetMode() != DefaultFileMode {
+		t.Errorf("Expected mode %o, got %o", DefaultFileMode, file.GetMode())
+	}
+	if file.GetOverwrite() != FileOverwriteReplace {
+		t.Errorf("Expected overwrite %s, got %s", FileOverwriteReplace, file.GetOverwrite())
+	}
+
+	file = FileConfig{Path: "/etc/app.conf", Content: "YT1i", Encoding: FileEncodingBase64, Mode: "600", Overwrite: FileOverwriteSkip}
+	content, err = file.GetContent()
+	if err != nil || string(content) != "a=b" {
+		t.Errorf("Expected decoded content a=b, got %q (%v)", content, err)
+	}
+	if file.GetMode() != os.FileMode(0600) {
+		t.Errorf("Expected mode 600, got %o", file.GetMode())
+	}
+	if file.GetOverwrite() != FileOverwriteSkip {
+		t.Errorf("Expected overwrite %s, got %s", FileOverwriteSkip, file.GetOverwrite())
+	}
+}
+
+func TestRunConfig_Validate_Files(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []FileConfig
+		errorMsg string
+	}{
+		{
+			name:     "Missing path",
+			files:    []FileConfig{{Content: "x"}},
+			errorMsg: "files[0].path: is required",
+		},
+		{
+			name:     "Relative path",
+			files:    []FileConfig{{Path: "etc/app.conf"}},
+			errorMsg: "files[0].path: must be an absolute, clean path, got etc/app.conf",
+		},
+		{
+			name:     "Unclean path",
+			files:    []FileConfig{{Path: "/etc/../app.conf"}},
+			errorMsg: "files[0].path: must be an absolute, clean path, got /etc/../app.conf",
+		},
+		{
+			name:     "Duplicate path",
+			files:    []FileConfig{{Path: "/app.conf"}, {Path: "/app.conf"}},
+			errorMsg: "files[1].path: duplicate path /app.conf",
+		},
+		{
+			name:     "Invalid base64",
+			files:    []FileConfig{{Path: "/app.conf", Content: "not base64!", Encoding: FileEncodingBase64}},
+			errorMsg: "files[0].content: invalid base64: illegal base64 data at input byte 3",
+		},
+		{
+			name:     "Unknown encoding",
+			files:    []FileConfig{{Path: "/app.conf", Encoding: "hex"}},
+			errorMsg: "files[0].encoding: unknown encoding hex",
+		},
+		{
+			name:     "Invalid mode",
+			files:    []FileConfig{{Path: "/app.conf", Mode: "rw-r--r--"}},
+			errorMsg: "files[0].mode: invalid mode rw-r--r--, expected octal permissions such as 0440",
+		},
+		{
+			name:     "Unknown overwrite policy",
+			files:    []FileConfig{{Path: "/app.conf", Overwrite: "append"}},
+			errorMsg: "files[0].overwrite: unknown overwrite policy append",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := RunConfig{ImageConfig: &ImageConfig{Cmd: []string{"/bin/app"}}, Files: tt.files}
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
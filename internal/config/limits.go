@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RlimitConfig is a resource limit such as RLIMIT_NOFILE, as in the OCI
+// runtime spec.
+type RlimitConfig struct {
+	Type string `json:"type"`
+	Soft uint64 `json:"soft"`
+	Hard uint64 `json:"hard"`
+}
+
+// rlimitNames are the resource limits that can be configured, indexed by
+// their number.
+var rlimitNames = []string{
+	"RLIMIT_CPU", "RLIMIT_FSIZE", "RLIMIT_DATA", "RLIMIT_STACK", "RLIMIT_CORE",
+	"RLIMIT_RSS", "RLIMIT_NPROC", "RLIMIT_NOFILE", "RLIMIT_MEMLOCK",
+	"RLIMIT_AS", "RLIMIT_LOCKS", "RLIMIT_SIGPENDING", "RLIMIT_MSGQUEUE",
+	"RLIMIT_NICE", "RLIMIT_RTPRIO", "RLIMIT_RTTIME",
+}
+
+// capabilityNames are the Linux capabilities, indexed by their number.
+var capabilityNames = []string{
+	"CAP_CHOWN", "CAP_DAC_OVERRIDE", "CAP_DAC_READ_SEARCH", "CAP_FOWNER",
+	"CAP_FSETID", "CAP_KILL", "CAP_SETGID", "CAP_SETUID", "CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE", "CAP_NET_BIND_SERVICE", "CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN", "CAP_NET_RAW", "CAP_IPC_LOCK", "CAP_IPC_OWNER",
+	"CAP_SYS_MODULE", "CAP_SYS_RAWIO", "CAP_SYS_CHROOT", "CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT", "CAP_SYS_ADMIN", "CAP_SYS_BOOT", "CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE", "CAP_SYS_TIME", "CAP_SYS_TTY_CONFIG", "CAP_MKNOD",
+	"CAP_LEASE", "CAP_AUDIT_WRITE", "CAP_AUDIT_CONTROL", "CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE", "CAP_MAC_ADMIN", "CAP_SYSLOG", "CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND", "CAP_AUDIT_READ", "CAP_PERFMON", "CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// ParseCapability resolves a capability name such as "CAP_NET_ADMIN" or
+// "NET_ADMIN" to its number.
+func ParseCapability(name string) (uintptr, error) {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "CAP_") {
+		name = "CAP_" + name
+	}
+	for i, capability := range capabilityNames {
+		if capability == name {
+			return uintptr(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown capability %s", name)
+}
+
+// ParseRlimit resolves a resource limit name such as "RLIMIT_NOFILE" to its
+// number.
+func ParseRlimit(name string) (int, error) {
+	for i, rlimit := range rlimitNames {
+		if rlimit == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown resource limit %s", name)
+}
+
+// GetCapabilities returns the application's capabilities by number.
+func (c *RunConfig) GetCapabilities() ([]uintptr, error) {
+	caps := make([]uintptr, 0, len(c.Capabilities))
+	for _, name := range c.Capabilities {
+		capability, err := ParseCapability(name)
+		if err != nil {
+			return nil, err
+		}
+		caps = append(caps, capability)
+	}
+	return caps, nil
+}
+
+func (r RlimitConfig) validate(path string, p *problems) {
+	if _, err := ParseRlimit(r.Type); err != nil {
+		p.add(fieldPath(path, "type"), "%v", err)
+	}
+	if r.Soft > r.Hard {
+		p.add(fieldPath(path, "soft"), "must not exceed the hard limit %d", r.Hard)
+	}
+}
+
+func (c *RunConfig) validateLimits(p *problems) {
+	seen := make(map[string]bool)
+	for i, rlimit := range c.Rlimits {
+		path := indexPath("rlimits", i)
+		rlimit.validate(path, p)
+		if seen[rlimit.Type] {
+			p.add(fieldPath(path, "type"), "duplicate resource limit %s", rlimit.Type)
+		}
+		seen[rlimit.Type] = true
+	}
+
+	for i, name := range c.Capabilities {
+		if _, err := ParseCapability(name); err != nil {
+			p.add(indexPath("capabilities", i), "%v", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCapability(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected uintptr
+	}{
+		{name: "CAP_CHOWN", expected: 0},
+		{name: "CAP_NET_BIND_SERVICE", expected: 10},
+		{name: "net_admin", expected: 12},
+		{name: "CAP_CHECKPOINT_RESTORE", expected: 40},
+	}
+
+	for _, tt := range tests {
+		capability, err := ParseCapability(tt.name)
+		if err != nil {
+			t.Errorf("ParseCapability(%s) failed: %v", tt.name, err)
+		} else if capability != tt.expected {
+			t.Errorf("ParseCapability(%s): expected %d, got %d", tt.name, tt.expected, capability)
+		}
+	}
+
+	if _, err := ParseCapability("CAP_FLY"); err == nil || err.Error() != "unknown capability CAP_FLY" {
+		t.Errorf("Expected unknown capability error, got %v", err)
+	}
+}
+
+func TestRunConfig_GetCapabilities(t *testing.T) {
+	config := RunConfig{Capabilities: []string{"NET_BIND_SERVICE", "CAP_SYS_TIME"}}
+
+	caps, err := config.GetCapabilities()
+	if err != nil {
+		t.Fatalf("GetCapabilities failed: %v", err)
+	}
+	if !reflect.DeepEqual(caps, []uintptr{10, 25}) {
+		t.Errorf("Expected [10 25], got %v", caps)
+	}
+}
+
+func TestRunConfig_Validate_Limits(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   RunConfig
+		errorMsg string
+	}{
+		{
+			name:     "Unknown resource limit",
+			config:   RunConfig{Rlimits: []RlimitConfig{{Type: "RLIMIT_FILES", Soft: 1, Hard: 1}}},
+			errorMsg: "rlimits[0].type: unknown resource limit RLIMIT_FILES",
+		},
+		{
+			name:     "Soft above hard",
+			config:   RunConfig{Rlimits: []RlimitConfig{{Type: "RLIMIT_NOFILE", Soft: 2048, Hard: 1024}}},
+			errorMsg: "rlimits[0].soft: must not exceed the hard limit 1024",
+		},
+		{
+			name: "Duplicate resource limit",
+			config: RunConfig{Rlimits: []RlimitConfig{
+				{Type: "RLIMIT_NOFILE", Soft: 1024, Hard: 1024},
+				{Type: "RLIMIT_NOFILE", Soft: 2048, Hard: 2048},
+			}},
+			errorMsg: "rlimits[1].type: duplicate resource limit RLIMIT_NOFILE",
+		},
+		{
+			name:     "Unknown capability",
+			config:   RunConfig{Capabilities: []string{"CAP_KILL", "CAP_FLY"}},
+			errorMsg: "capabilities[1]: unknown capability CAP_FLY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.ImageConfig = &ImageConfig{Cmd: []string{"/bin/app"}}
+			err := tt.config.Validate()
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
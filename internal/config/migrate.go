@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CurrentVersion is the run.json format this init understands.
+const CurrentVersion = 3
+
+// unversionedVersion is the version of documents without one. They are
+// read as version 2, so cmdOverride is parsed as shell words: only an
+// explicit version 1 keeps splitting it on whitespace.
+const unversionedVersion = 2
+
+// migrations upgrade a decoded document from version i+1 to version i+2
+// and report whether they had to change it. Every format change that would
+// make an older document mean something else adds one here and bumps
+// CurrentVersion.
+var migrations = []func(doc map[string]any) (bool, error){
+	migrateV1CmdOverride,
+	migrateV2Gateways,
+}
+
+// migrate upgrades doc to CurrentVersion in place. It reports whether doc
+// had to be changed for that.
+func migrate(doc map[string]any) (bool, error) {
+	version, err := documentVersion(doc)
+	if err != nil {
+		return false, ValidationErrors{{Path: "version", Message: err.Error()}}
+	}
+
+	migrated := false
+	for v := version; v < CurrentVersion; v++ {
+		changed, err := migrations[v-1](doc)
+		if err != nil {
+			return false, fmt.Errorf("migrating from version %d: %w", v, err)
+		}
+		migrated = migrated || changed
+	}
+	return migrated, nil
+}
+
+func documentVersion(doc map[string]any) (int, error) {
+	raw, ok := doc["version"]
+	if !ok {
+		return unversionedVersion, nil
+	}
+
+	number, ok := raw.(float64)
+	if !ok || number != math.Trunc(number) {
+		return 0, fmt.Errorf("must be an integer")
+	}
+	if number < 1 {
+		return 0, fmt.Errorf("must be at least 1")
+	}
+	if number > CurrentVersion {
+		return 0, fmt.Errorf("version %v is newer than the supported version %d", number, CurrentVersion)
+	}
+	version := int(number)
+	return version, nil
+}
+
+// migrateV1CmdOverride keeps the meaning of cmdOverride, which version 1
+// split on whitespace only, by quoting every word for SplitShellWords.
+func migrateV1CmdOverride(doc map[string]any) (bool, error) {
+	cmd, ok := doc["cmdOverride"].(string)
+	if !ok {
+		// a missing field needs nothing and a wrong type is left for the
+		// decoder to report
+		return false, nil
+	}
+
+	words := strings.Fields(cmd)
+	for i, word := range words {
+		words[i] = quoteShellWord(word)
+	}
+	quoted := strings.Join(words, " ")
+	if quoted == cmd {
+		return false, nil
+	}
+	doc["cmdOverride"] = quoted
+	return true, nil
+}
+
+// migrateV2Gateways turns the gateways of ipConfigs, which version 2 wrote
+// as CIDRs, into plain addresses.
+func migrateV2Gateways(doc map[string]any) (bool, error) {
+	ipConfigs, ok := doc["ipConfigs"].([]any)
+	if !ok {
+		return false, nil
+	}
+
+	changed := false
+	for _, raw := range ipConfigs {
+		ipConfig, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		gateway, ok := ipConfig["gateway"].(string)
+		if !ok {
+			continue
+		}
+		if addr, _, found := strings.Cut(gateway, "/"); found {
+			ipConfig["gateway"] = addr
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// quoteShellWord quotes s so that SplitShellWords returns it unchanged,
+// with or without expansion.
+func quoteShellWord(s string) string {
+	if s != "" && !strings.ContainsAny(s, "'\"\\$ \t\n") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig_Migration(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name:     "Unversioned cmdOverride is parsed as shell words",
+			data:     `{"cmdOverride": "sh -c \"echo hello world\""}`,
+			expected: []string{"sh", "-c", "echo hello world"},
+		},
+		{
+			name:     "Version 1 cmdOverride keeps quotes",
+			data:     `{"version": 1, "imageConfig": {"entrypoint": ["/bin/sh", "-c"]}, "cmdOverride": "echo \"hi  there\" it's $HOME"}`,
+			expected: []string{"/bin/sh", "-c", "echo", `"hi`, `there"`, "it's", "$HOME"},
+		},
+		{
+			name:     "Version 1 cmdOverride splits on whitespace",
+			data:     `{"version": 1, "cmdOverride": "run a\\b"}`,
+			expected: []string{"run", `a\b`},
+		},
+		{
+			name:     "Current cmdOverride is parsed as shell words",
+			data:     `{"version": 2, "cmdOverride": "echo \"hi  there\""}`,
+			expected: []string{"echo", "hi  there"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseConfig([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseConfig failed: %v", err)
+			}
+			if config.Version != CurrentVersion {
+				t.Errorf("Expected version %d, got %d", CurrentVersion, config.Version)
+			}
+			if cmd := config.GetCommand(); !reflect.DeepEqual(cmd, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, cmd)
+			}
+		})
+	}
+}
+
+func TestParseConfig_UnversionedUnbalancedQuote(t *testing.T) {
+	config, err := ParseConfig([]byte(`{"cmdOverride": "sh -c \"echo"}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "cmdOverride: ") {
+		t.Errorf("Expected the unbalanced quote to be reported, got %v", err)
+	}
+}
+
+func TestParseConfig_MigrateGateways(t *testing.T) {
+	data := `{"version": 2, "ipConfigs": [
+		{"ip": "10.0.0.2/24", "gateway": "10.0.0.1/24"},
+		{"ip": "fdaa::2/64", "gateway": "fdaa::1"}
+	]}`
+
+	config, err := ParseConfig([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+
+	for i, expected := range []string{"10.0.0.1", "fdaa::1"} {
+		if gateway := config.IPConfigs[i].Gateway.String(); gateway != expected {
+			t.Errorf("Expected gateway %s, got %s", expected, gateway)
+		}
+	}
+	if ip := config.IPConfigs[0].IP.String(); ip != "10.0.0.2/24" {
+		t.Errorf("Expected IP 10.0.0.2/24, got %s", ip)
+	}
+}
+
+func TestParseConfig_MigrationRoundTrip(t *testing.T) {
+	config, err := ParseConfig([]byte(`{"version": 1, "cmdOverride": "printf '%s\\n' x"}`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+
+	data, err := config.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	reparsed, err := ParseConfig([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+
+	expected := []string{"printf", `'%s\n'`, "x"}
+	if cmd := reparsed.GetCommand(); !reflect.DeepEqual(cmd, expected) {
+		t.Errorf("Expected %q, got %q", expected, cmd)
+	}
+}
+
+func TestParseConfig_VersionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		errorMsg string
+	}{
+		{
+			name:     "Newer version",
+			data:     `{"version": 4}`,
+			errorMsg: "failed to parse config JSON: version: version 4 is newer than the supported version 3",
+		},
+		{
+			name:     "Zero version",
+			data:     `{"version": 0}`,
+			errorMsg: "failed to parse config JSON: version: must be at least 1",
+		},
+		{
+			name:     "Not an integer",
+			data:     `{"version": "2"}`,
+			errorMsg: "failed to parse config JSON: version: must be an integer",
+		},
+		{
+			name:     "Type errors in migrated documents have no position",
+			data:     "{\n  \"version\": 1,\n  \"cmdOverride\": \"echo 'hi'\",\n  \"killTimeout\": \"5\"\n}",
+			errorMsg: "failed to parse config JSON: killTimeout: cannot use string as int",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.data))
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestQuoteShellWord(t *testing.T) {
+	words := []string{"plain", "", "a b", `it's`, `"q"`, `back\slash`, "$VAR", "${VAR}"}
+	for _, word := range words {
+		split, err := SplitShellWords(quoteShellWord(word), func(string) (string, bool) { return "x", true })
+		if err != nil {
+			t.Fatalf("SplitShellWords(%q) failed: %v", quoteShellWord(word), err)
+		}
+		if len(split) != 1 || split[0] != word {
+			t.Errorf("Expected %q, got %q", word, split)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ociSpec is the part of an OCI runtime spec config.json that can be
+// mapped onto a RunConfig. Everything else is reported as unsupported.
+type ociSpec struct {
+	OCIVersion string      `json:"ociVersion"`
+	Process    *ociProcess `json:"process"`
+	Root       *ociRoot    `json:"root"`
+	Hostname   string      `json:"hostname"`
+	Mounts     []ociMount  `json:"mounts"`
+}
+
+type ociProcess struct {
+	Terminal        bool             `json:"terminal"`
+	User            ociUser          `json:"user"`
+	Args            []string         `json:"args"`
+	Env             []string         `json:"env"`
+	Cwd             string           `json:"cwd"`
+	Capabilities    *ociCapabilities `json:"capabilities"`
+	Rlimits         []RlimitConfig   `json:"rlimits"`
+	NoNewPrivileges bool             `json:"noNewPrivileges"`
+}
+
+type ociUser struct {
+	UID            uint32   `json:"uid"`
+	GID            uint32   `json:"gid"`
+	Umask          *uint32  `json:"umask"`
+	AdditionalGids []uint32 `json:"additionalGids"`
+}
+
+type ociCapabilities struct {
+	Bounding    []string `json:"bounding"`
+	Effective   []string `json:"effective"`
+	Inheritable []string `json:"inheritable"`
+	Permitted   []string `json:"permitted"`
+	Ambient     []string `json:"ambient"`
+}
+
+type ociRoot struct {
+	Path     string `json:"path"`
+	Readonly bool   `json:"readonly"`
+}
+
+type ociMount struct {
+	Destination string   `json:"destination"`
+	Type        string   `json:"type"`
+	Source      string   `json:"source"`
+	Options     []string `json:"options"`
+}
+
+// ociEssentialMounts are set up by init itself, see
+// system.MountEssential, so the ones in a config.json are dropped.
+var ociEssentialMounts = map[string]bool{
+	"/proc":          true,
+	"/dev":           true,
+	"/dev/pts":       true,
+	"/dev/shm":       true,
+	"/dev/mqueue":    true,
+	"/sys":           true,
+	"/sys/fs/cgroup": true,
+}
+
+// isOCISpec reports whether a decoded document is an OCI runtime spec
+// rather than a run.json.
+func isOCISpec(doc map[string]any) bool {
+	_, ok := doc["ociVersion"]
+	return ok
+}
+
+// decodeOCI maps an OCI runtime spec config.json onto a RunConfig. The
+// process becomes the application; settings that have no equivalent in a
+// guest are returned as warnings.
+func decodeOCI(data []byte, raw any) (*RunConfig, []string, error) {
+	var spec ociSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, nil, describeJSONError(data, err)
+	}
+	if major, _, _ := strings.Cut(spec.OCIVersion, "."); major != "1" {
+		return nil, nil, ValidationErrors{{Path: "ociVersion", Message: fmt.Sprintf("unsupported version %q", spec.OCIVersion)}}
+	}
+
+	var unsupported problems
+	unknownFields(raw, reflect.TypeOf(spec), "", &unsupported)
+	for i := range unsupported.errs {
+		unsupported.errs[i].Message = "not supported, ignored"
+	}
+
+	config := &RunConfig{
+		Version:  CurrentVersion,
+		Hostname: spec.Hostname,
+	}
+	if spec.Root != nil && spec.Root.Readonly {
+		unsupported.add("root.readonly", "not supported, the root filesystem is mounted read-write")
+	}
+	if spec.Process != nil {
+		config.mapOCIProcess(spec.Process, &unsupported)
+	}
+	config.mapOCIMounts(spec.Mounts, &unsupported)
+
+	warnings := make([]string, len(unsupported.errs))
+	for i, err := range unsupported.errs {
+		warnings[i] = err.Error()
+	}
+	return config, warnings, nil
+}
+
+func (c *RunConfig) mapOCIProcess(process *ociProcess, p *problems) {
+	c.TTY = process.Terminal
+	c.ImageConfig = &ImageConfig{
+		Cmd:        process.Args,
+		Env:        process.Env,
+		WorkingDir: process.Cwd,
+		User:       strconv.FormatUint(uint64(process.User.UID), 10) + ":" + strconv.FormatUint(uint64(process.User.GID), 10),
+	}
+	if process.User.Umask != nil {
+		p.add("process.user.umask", "not supported, ignored")
+	}
+	if len(process.User.AdditionalGids) > 0 {
+		p.add("process.user.additionalGids", "not supported, the application only gets its primary group")
+	}
+	if process.NoNewPrivileges {
+		p.add("process.noNewPrivileges", "not supported, ignored")
+	}
+
+	c.Rlimits = process.Rlimits
+
+	if process.Capabilities != nil {
+		c.Capabilities = process.Capabilities.Effective
+		if process.User.UID == 0 && len(process.Capabilities.Effective) < len(capabilityNames) {
+			p.add("process.capabilities", "not applied to root, which keeps every capability")
+		}
+	}
+}
+
+// mapOCIMounts reports every mount init does not set up itself. Mounts
+// from run.json are not set up either, so there is nothing to map them to.
+func (c *RunConfig) mapOCIMounts(mounts []ociMount, p *problems) {
+	for i, m := range mounts {
+		if ociEssentialMounts[m.Destination] {
+			continue
+		}
+
+		bind := m.Type == "bind"
+		for _, option := range m.Options {
+			bind = bind || option == "bind" || option == "rbind"
+		}
+		if bind {
+			p.add(indexPath("mounts", i), "bind mount of %s to %s cannot be set up in a guest, ignored", m.Source, m.Destination)
+			continue
+		}
+		p.add(indexPath("mounts", i), "%s mount of %s to %s is not supported, ignored", m.Type, m.Source, m.Destination)
+	}
+}
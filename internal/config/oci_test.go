@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ociConfig is trimmed from the output of runc spec.
+const ociConfig = `{
+	"ociVersion": "1.0.2-dev",
+	"process": {
+		"terminal": true,
+		"user": {"uid": 1000, "gid": 1000, "additionalGids": [27]},
+		"args": ["/usr/bin/app", "--serve"],
+		"env": ["PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin", "TERM=xterm"],
+		"cwd": "/srv",
+		"capabilities": {
+			"bounding": ["CAP_AUDIT_WRITE", "CAP_KILL", "CAP_NET_BIND_SERVICE"],
+			"effective": ["CAP_NET_BIND_SERVICE"],
+			"permitted": ["CAP_NET_BIND_SERVICE"],
+			"ambient": ["CAP_NET_BIND_SERVICE"]
+		},
+		"rlimits": [{"type": "RLIMIT_NOFILE", "hard": 4096, "soft": 1024}],
+		"noNewPrivileges": true
+	},
+	"root": {"path": "rootfs", "readonly": true},
+	"hostname": "runc",
+	"mounts": [
+		{"destination": "/proc", "type": "proc", "source": "proc"},
+		{"destination": "/dev", "type": "tmpfs", "source": "tmpfs", "options": ["nosuid", "strictatime", "mode=755", "size=65536k"]},
+		{"destination": "/tmp", "type": "tmpfs", "source": "tmpfs", "options": ["nosuid", "nodev"]},
+		{"destination": "/data", "type": "none", "source": "/srv/data", "options": ["rbind", "rw"]}
+	],
+	"linux": {
+		"namespaces": [{"type": "pid"}, {"type": "mount"}],
+		"maskedPaths": ["/proc/kcore"]
+	}
+}`
+
+func TestParseConfig_OCI(t *testing.T) {
+	config, warnings, err := ParseConfigLenient([]byte(ociConfig))
+	if err != nil {
+		t.Fatalf("ParseConfigLenient failed: %v", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected the mapped config to be valid, got %v", err)
+	}
+
+	expectedCommand := []string{"/usr/bin/app", "--serve"}
+	if cmd := config.GetCommand(); !reflect.DeepEqual(cmd, expectedCommand) {
+		t.Errorf("Expected command %v, got %v", expectedCommand, cmd)
+	}
+	if config.GetUser() != "1000:1000" {
+		t.Errorf("Expected user 1000:1000, got %s", config.GetUser())
+	}
+	if config.GetWorkingDir() != "/srv" {
+		t.Errorf("Expected working dir /srv, got %s", config.GetWorkingDir())
+	}
+	if !config.TTY || config.Hostname != "runc" {
+		t.Errorf("Expected tty and hostname runc, got %v and %s", config.TTY, config.Hostname)
+	}
+	if env := config.GetEnvironment(); env[len(env)-1] != "TERM=xterm" {
+		t.Errorf("Expected the process env, got %v", env)
+	}
+
+	expectedRlimits := []RlimitConfig{{Type: "RLIMIT_NOFILE", Soft: 1024, Hard: 4096}}
+	if !reflect.DeepEqual(config.Rlimits, expectedRlimits) {
+		t.Errorf("Expected rlimits %v, got %v", expectedRlimits, config.Rlimits)
+	}
+	if !reflect.DeepEqual(config.Capabilities, []string{"CAP_NET_BIND_SERVICE"}) {
+		t.Errorf("Expected capabilities [CAP_NET_BIND_SERVICE], got %v", config.Capabilities)
+	}
+
+	if len(config.Mounts) > 0 {
+		t.Errorf("Expected no mounts, got %v", config.Mounts)
+	}
+
+	expectedWarnings := []string{
+		"linux: not supported, ignored",
+		"root.readonly: not supported, the root filesystem is mounted read-write",
+		"process.user.additionalGids: not supported, the application only gets its primary group",
+		"process.noNewPrivileges: not supported, ignored",
+		"mounts[2]: tmpfs mount of tmpfs to /tmp is not supported, ignored",
+		"mounts[3]: bind mount of /srv/data to /data cannot be set up in a guest, ignored",
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("Expected warnings:\n%s\ngot:\n%s", strings.Join(expectedWarnings, "\n"), strings.Join(warnings, "\n"))
+	}
+}
+
+func TestParseConfig_OCIRootCapabilities(t *testing.T) {
+	data := `{"ociVersion": "1.1.0", "process": {"args": ["sh"], "capabilities": {"effective": ["CAP_KILL"]}}}`
+
+	_, warnings, err := ParseConfigLenient([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseConfigLenient failed: %v", err)
+	}
+	expected := []string{"process.capabilities: not applied to root, which keeps every capability"}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected warnings %q, got %q", expected, warnings)
+	}
+}
+
+func TestParseConfig_OCIErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		errorMsg string
+	}{
+		{
+			name:     "Unsupported version",
+			data:     `{"ociVersion": "2.0.0"}`,
+			errorMsg: `failed to parse config JSON: ociVersion: unsupported version "2.0.0"`,
+		},
+		{
+			name:     "Type error",
+			data:     "{\"ociVersion\": \"1.0.0\",\n\"process\": {\"args\": \"sh\"}}",
+			errorMsg: "failed to parse config JSON: line 2, column 24: process.args: cannot use string as []string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.data))
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadConfig_DetectsOCI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(ociConfig), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// unsupported OCI settings are warnings even when decoding strictly
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if config.Hostname != "runc" {
+		t.Errorf("Expected hostname runc, got %s", config.Hostname)
+	}
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	RouteTypeUnicast     = "unicast"
+	RouteTypeBlackhole   = "blackhole"
+	RouteTypeUnreachable = "unreachable"
+	RouteTypeProhibit    = "prohibit"
+
+	// RouteDestinationDefault is the destination of a default route.
+	RouteDestinationDefault = "default"
+
+	// maxRouteTable is the highest routing table id.
+	maxRouteTable int64 = 1<<32 - 1
+)
+
+// RouteConfig is a static route, applied after the interfaces are
+// configured. Destination is a CIDR or "default". A unicast route, the
+// default type, goes through a gateway, a device or both; blackhole,
+// unreachable and prohibit routes drop matching traffic and have neither.
+// Table defaults to the main table.
+type RouteConfig struct {
+	Destination string `json:"destination"`
+	Gateway     string `json:"gateway,omitempty"`
+	Device      string `json:"device,omitempty"`
+	Metric      int    `json:"metric,omitempty"`
+	Table       int    `json:"table,omitempty"`
+	Type        string `json:"type,omitempty"`
+}
+
+// RuleConfig is a policy routing rule, as added by ip rule: traffic
+// matching every selector given is looked up in Table. A rule without
+// From or To applies to both IPv4 and IPv6. Priority defaults to one the
+// kernel picks.
+type RuleConfig struct {
+	Priority int    `json:"priority,omitempty"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Iif      string `json:"iif,omitempty"`
+	Oif      string `json:"oif,omitempty"`
+	Table    int    `json:"table"`
+}
+
+// GetType returns the type of the route.
+func (r RouteConfig) GetType() string {
+	if r.Type == "" {
+		return RouteTypeUnicast
+	}
+	return r.Type
+}
+
+// GetDestination returns the network the route is for. A default route is
+// for 0.0.0.0/0 unless its gateway is an IPv6 address.
+func (r RouteConfig) GetDestination() (*net.IPNet, error) {
+	if r.Destination == RouteDestinationDefault {
+		if gateway := net.ParseIP(r.Gateway); gateway != nil && gateway.To4() == nil {
+			return &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}, nil
+		}
+		return &net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)}, nil
+	}
+	return parseNetwork(r.Destination)
+}
+
+// String describes the route the way ip route does.
+func (r RouteConfig) String() string {
+	var b strings.Builder
+	if r.GetType() != RouteTypeUnicast {
+		b.WriteString(r.Type + " ")
+	}
+	b.WriteString(r.Destination)
+	if r.Gateway != "" {
+		b.WriteString(" via " + r.Gateway)
+	}
+	if r.Device != "" {
+		b.WriteString(" dev " + r.Device)
+	}
+	if r.Metric != 0 {
+		fmt.Fprintf(&b, " metric %d", r.Metric)
+	}
+	if r.Table != 0 {
+		fmt.Fprintf(&b, " table %d", r.Table)
+	}
+	return b.String()
+}
+
+// String describes the rule the way ip rule does.
+func (r RuleConfig) String() string {
+	var b strings.Builder
+	if r.Priority != 0 {
+		fmt.Fprintf(&b, "priority %d ", r.Priority)
+	}
+	for _, selector := range []struct{ name, value string }{
+		{"from", r.From}, {"to", r.To}, {"iif", r.Iif}, {"oif", r.Oif},
+	} {
+		if selector.value != "" {
+			b.WriteString(selector.name + " " + selector.value + " ")
+		}
+	}
+	fmt.Fprintf(&b, "table %d", r.Table)
+	return b.String()
+}
+
+// Validate checks a route given on its own, e.g. over the API.
+func (r RouteConfig) Validate() error {
+	var p problems
+	r.validate("", &p)
+	return p.err()
+}
+
+// parseNetwork parses a CIDR, keeping only the network.
+func parseNetwork(s string) (*net.IPNet, error) {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %s", s)
+	}
+	return network, nil
+}
+
+func (r RouteConfig) validate(path string, p *problems) {
+	destination, err := r.GetDestination()
+	switch {
+	case r.Destination == "":
+		p.add(fieldPath(path, "destination"), "is required")
+	case err != nil:
+		p.add(fieldPath(path, "destination"), "%v, expected a CIDR or %s", err, RouteDestinationDefault)
+	}
+
+	gateway := net.ParseIP(r.Gateway)
+	if r.Gateway != "" {
+		switch {
+		case gateway == nil:
+			p.add(fieldPath(path, "gateway"), "invalid IP address %s", r.Gateway)
+		case destination != nil && (gateway.To4() == nil) != (destination.IP.To4() == nil):
+			p.add(fieldPath(path, "gateway"), "%s is not in the address family of %s", r.Gateway, r.Destination)
+		}
+	}
+
+	if r.Device != "" && !isInterfaceName(r.Device) {
+		p.add(fieldPath(path, "device"), "invalid interface name %s", r.Device)
+	}
+	if r.Metric < 0 {
+		p.add(fieldPath(path, "metric"), "must not be negative")
+	}
+	if r.Table < 0 || int64(r.Table) > maxRouteTable {
+		p.add(fieldPath(path, "table"), "must be between 0 and %d", maxRouteTable)
+	}
+
+	switch r.GetType() {
+	case RouteTypeUnicast:
+		if r.Gateway == "" && r.Device == "" {
+			p.add(path, "a unicast route needs a gateway or a device")
+		}
+	case RouteTypeBlackhole, RouteTypeUnreachable, RouteTypeProhibit:
+		if r.Gateway != "" || r.Device != "" {
+			p.add(path, "a %s route takes no gateway or device", r.Type)
+		}
+	default:
+		p.add(fieldPath(path, "type"), "unknown route type %s", r.Type)
+	}
+}
+
+func (r RuleConfig) validate(path string, p *problems) {
+	var networks []*net.IPNet
+	for _, selector := range []struct{ name, value string }{{"from", r.From}, {"to", r.To}} {
+		if selector.value == "" {
+			continue
+		}
+		network, err := parseNetwork(selector.value)
+		if err != nil {
+			p.add(fieldPath(path, selector.name), "%v", err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	if len(networks) == 2 && (networks[0].IP.To4() == nil) != (networks[1].IP.To4() == nil) {
+		p.add(fieldPath(path, "to"), "%s is not in the address family of %s", r.To, r.From)
+	}
+
+	for _, selector := range []struct{ name, value string }{{"iif", r.Iif}, {"oif", r.Oif}} {
+		if selector.value != "" && !isInterfaceName(selector.value) {
+			p.add(fieldPath(path, selector.name), "invalid interface name %s", selector.value)
+		}
+	}
+	if r.Priority < 0 {
+		p.add(fieldPath(path, "priority"), "must not be negative")
+	}
+	if r.Table <= 0 || int64(r.Table) > maxRouteTable {
+		p.add(fieldPath(path, "table"), "must be between 1 and %d", maxRouteTable)
+	}
+}
+
+func (c *RunConfig) validateRoutes(p *problems) {
+	for i, route := range c.Routes {
+		route.validate(indexPath("routes", i), p)
+	}
+	for i, rule := range c.Rules {
+		rule.validate(indexPath("rules", i), p)
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestRouteConfig_GetDestination(t *testing.T) {
+	tests := []struct {
+		route    RouteConfig
+		expected string
+	}{
+		{route: RouteConfig{Destination: "10.1.2.3/8"}, expected: "10.0.0.0/8"},
+		{route: RouteConfig{Destination: "default", Gateway: "172.19.0.1"}, expected: "0.0.0.0/0"},
+		{route: RouteConfig{Destination: "default", Gateway: "fdaa::1"}, expected: "::/0"},
+		{route: RouteConfig{Destination: "default", Type: RouteTypeBlackhole}, expected: "0.0.0.0/0"},
+	}
+
+	for _, tt := range tests {
+		destination, err := tt.route.GetDestination()
+		if err != nil {
+			t.Errorf("GetDestination(%s) failed: %v", tt.route, err)
+		} else if destination.String() != tt.expected {
+			t.Errorf("GetDestination(%s): expected %s, got %s", tt.route, tt.expected, destination)
+		}
+	}
+}
+
+func TestRouteConfig_String(t *testing.T) {
+	tests := []struct {
+		route    RouteConfig
+		expected string
+	}{
+		{
+			route:    RouteConfig{Destination: "10.0.0.0/8", Gateway: "172.19.0.1", Device: "eth1", Metric: 10, Table: 100},
+			expected: "10.0.0.0/8 via 172.19.0.1 dev eth1 metric 10 table 100",
+		},
+		{
+			route:    RouteConfig{Destination: "169.254.169.254/32", Type: RouteTypeBlackhole},
+			expected: "blackhole 169.254.169.254/32",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.route.String(); got != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, got)
+		}
+	}
+
+	rule := RuleConfig{Priority: 100, From: "10.0.0.0/8", Oif: "eth1", Table: 100}
+	if got := rule.String(); got != "priority 100 from 10.0.0.0/8 oif eth1 table 100" {
+		t.Errorf("Expected the rule in ip rule syntax, got %s", got)
+	}
+}
+
+func TestRunConfig_Validate_Routes(t *testing.T) {
+	tests := []struct {
+		name     string
+		routes   []RouteConfig
+		rules    []RuleConfig
+		errorMsg string
+	}{
+		{
+			name:     "Missing destination",
+			routes:   []RouteConfig{{Gateway: "172.19.0.1"}},
+			errorMsg: "routes[0].destination: is required",
+		},
+		{
+			name:     "Invalid destination",
+			routes:   []RouteConfig{{Destination: "10.0.0.0", Gateway: "172.19.0.1"}},
+			errorMsg: "routes[0].destination: invalid CIDR 10.0.0.0, expected a CIDR or default",
+		},
+		{
+			name:     "Invalid gateway",
+			routes:   []RouteConfig{{Destination: "10.0.0.0/8", Gateway: "gateway"}},
+			errorMsg: "routes[0].gateway: invalid IP address gateway",
+		},
+		{
+			name:     "Gateway of another family",
+			routes:   []RouteConfig{{Destination: "fd00::/8", Gateway: "172.19.0.1"}},
+			errorMsg: "routes[0].gateway: 172.19.0.1 is not in the address family of fd00::/8",
+		},
+		{
+			name:     "Unicast without gateway or device",
+			routes:   []RouteConfig{{Destination: "10.0.0.0/8", Metric: -1}},
+			errorMsg: "routes[0].metric: must not be negative; routes[0]: a unicast route needs a gateway or a device",
+		},
+		{
+			name:     "Blackhole with gateway",
+			routes:   []RouteConfig{{Destination: "169.254.169.254/32", Gateway: "169.254.0.1", Type: RouteTypeBlackhole}},
+			errorMsg: "routes[0]: a blackhole route takes no gateway or device",
+		},
+		{
+			name:     "Unknown type and invalid table",
+			routes:   []RouteConfig{{Destination: "10.0.0.0/8", Device: "eth1", Table: -1, Type: "nat"}},
+			errorMsg: "routes[0].table: must be between 0 and 4294967295; routes[0].type: unknown route type nat",
+		},
+		{
+			name:     "Rule without table",
+			rules:    []RuleConfig{{From: "10.0.0.0/8"}},
+			errorMsg: "rules[0].table: must be between 1 and 4294967295",
+		},
+		{
+			name:     "Rule selectors",
+			rules:    []RuleConfig{{From: "10.0.0.0/8", To: "fd00::/8", Iif: "eth0/1", Priority: -1, Table: 100}},
+			errorMsg: "rules[0].to: fd00::/8 is not in the address family of 10.0.0.0/8; rules[0].iif: invalid interface name eth0/1; rules[0].priority: must not be negative",
+		},
+		{
+			name:     "Rule invalid network",
+			rules:    []RuleConfig{{To: "10.0.0.1", Table: 100}},
+			errorMsg: "rules[0].to: invalid CIDR 10.0.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := RunConfig{ImageConfig: &ImageConfig{Cmd: []string{"/bin/app"}}, Routes: tt.routes, Rules: tt.rules}
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestRunConfig_Validate_ValidRoutes(t *testing.T) {
+	config := RunConfig{
+		ImageConfig: &ImageConfig{Cmd: []string{"/bin/app"}},
+		Routes: []RouteConfig{
+			{Destination: "10.0.0.0/8", Gateway: "172.19.0.1", Table: 100},
+			{Destination: "default", Gateway: "fdaa::1", Device: "eth1", Metric: 200},
+			{Destination: "192.168.0.0/24", Device: "eth1"},
+			{Destination: "169.254.169.254/32", Type: RouteTypeBlackhole},
+		},
+		Rules: []RuleConfig{{From: "10.0.0.0/8", Table: 100}, {Oif: "eth1", Table: 100}},
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected valid routes, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// SchemaDialect is the JSON Schema draft Schema generates.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaConstraints narrows the schema of individual fields beyond what
+// their Go type says, by struct type and JSON field name.
+var schemaConstraints = map[reflect.Type]map[string]map[string]any{
+	reflect.TypeOf(RunConfig{}): {
+		"version":        {"minimum": 1, "maximum": CurrentVersion},
+		"shutdownAction": {"enum": []string{ShutdownReboot, ShutdownPowerOff}},
+		"shutdownLinger": {"minimum": 0, "maximum": int(MaxShutdownLinger / time.Second)},
+		"signalTarget":   {"enum": []string{SignalTargetProcess, SignalTargetGroup}},
+		"killTimeout":    {"minimum": 0},
+		"etcMode":        {"enum": []string{EtcModeReplace, EtcModeMerge}},
+	},
+	reflect.TypeOf(RestartConfig{}): {
+		"policy":         {"enum": []string{RestartNo, RestartOnFailure, RestartAlways}},
+		"maxRetries":     {"minimum": 0},
+		"initialBackoff": {"minimum": 0},
+		"maxBackoff":     {"minimum": 0},
+		"resetAfter":     {"minimum": 0},
+	},
+	reflect.TypeOf(HookConfig{}): {
+		"command":   {"minItems": 1},
+		"timeout":   {"minimum": 0},
+		"onFailure": {"enum": []string{HookFailureAbort, HookFailureIgnore}},
+	},
+	reflect.TypeOf(ProcessConfig{}): {
+		"name":    {"minLength": 1},
+		"command": {"minItems": 1},
+	},
+	reflect.TypeOf(Mount{}): {
+		"mountPath":  {"minLength": 1},
+		"devicePath": {"minLength": 1},
+	},
+	reflect.TypeOf(FileConfig{}): {
+		"path":      {"pattern": "^/"},
+		"encoding":  {"enum": []string{FileEncodingPlain, FileEncodingBase64}},
+		"mode":      {"pattern": "^0?[0-7]{1,3}$"},
+		"overwrite": {"enum": []string{FileOverwriteReplace, FileOverwriteSkip, FileOverwriteFail}},
+	},
+	reflect.TypeOf(RouteConfig{}): {
+		"metric": {"minimum": 0},
+		"table":  {"minimum": 0, "maximum": maxRouteTable},
+		"type":   {"enum": []string{RouteTypeUnicast, RouteTypeBlackhole, RouteTypeUnreachable, RouteTypeProhibit}},
+	},
+	reflect.TypeOf(RuleConfig{}): {
+		"priority": {"minimum": 0},
+		"iif":      {"minLength": 1, "maxLength": maxInterfaceName},
+		"oif":      {"minLength": 1, "maxLength": maxInterfaceName},
+		"table":    {"minimum": 1, "maximum": maxRouteTable},
+	},
+	reflect.TypeOf(NetworkWaitConfig{}): {
+		"timeout":   {"minimum": 0},
+		"onTimeout": {"enum": []string{NetworkWaitAbort, NetworkWaitWarn}},
+	},
+	reflect.TypeOf(IPConfig{}): {
+		"interface": {"minLength": 1, "maxLength": maxInterfaceName},
+		"mtu":       {"minimum": minIPv4MTU, "maximum": maxMTU},
+	},
+	reflect.TypeOf(SecretConfig{}): {
+		"env":  {"pattern": "^[A-Za-z_][A-Za-z0-9_]*$"},
+		"file": {"pattern": "^[^/]+$"},
+		"mode": {"pattern": "^0?[0-7]{1,3}$"},
+	},
+}
+
+// schemaRequired lists the fields Validate insists on, by struct type.
+var schemaRequired = map[reflect.Type][]string{
+	reflect.TypeOf(HookConfig{}):    {"command"},
+	reflect.TypeOf(ProcessConfig{}): {"name", "command"},
+	reflect.TypeOf(Mount{}):         {"mountPath", "devicePath"},
+	reflect.TypeOf(EtcHost{}):       {"host", "ip"},
+	reflect.TypeOf(IPConfig{}):      {"ip"},
+	reflect.TypeOf(SecretConfig{}):  {"name", "value"},
+	reflect.TypeOf(FileConfig{}):    {"path"},
+	reflect.TypeOf(RouteConfig{}):   {"destination"},
+	reflect.TypeOf(RuleConfig{}):    {"table"},
+}
+
+// Schema returns a JSON Schema for run.json generated from RunConfig. It
+// checks the shape of a document; Validate still has the final say on
+// whether its values make sense together.
+func Schema() ([]byte, error) {
+	g := schemaGenerator{defs: make(map[string]any)}
+	root := g.object(reflect.TypeOf(RunConfig{}))
+	root["$schema"] = SchemaDialect
+	root["title"] = "run.json"
+	root["$defs"] = g.defs
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	}
+	return map[string]any{}
+}
+
+func (g *schemaGenerator) object(t reflect.Type) map[string]any {
+	fields := t
+	if shadow, ok := jsonShadows[t]; ok {
+		fields = shadow
+	}
+
+	properties := make(map[string]any)
+	for name, field := range jsonFields(fields) {
+		property := g.schema(field.Type)
+		for keyword, value := range schemaConstraints[t][name] {
+			property[keyword] = value
+		}
+		properties[name] = property
+	}
+
+	object := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if required := schemaRequired[t]; len(required) > 0 {
+		object["required"] = required
+	}
+	return object
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	if schema["$schema"] != SchemaDialect {
+		t.Errorf("Expected dialect %s, got %v", SchemaDialect, schema["$schema"])
+	}
+	if schema["additionalProperties"] != false {
+		t.Error("Expected unknown top-level fields to be rejected")
+	}
+
+	properties := schema["properties"].(map[string]any)
+	for name := range jsonFields(reflect.TypeOf(RunConfig{})) {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("Expected property %s", name)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		schema   any
+		expected string
+	}{
+		{
+			name:     "Scalar",
+			schema:   properties["hostname"],
+			expected: `{"type":"string"}`,
+		},
+		{
+			name:     "Constraints",
+			schema:   properties["version"],
+			expected: `{"maximum":3,"minimum":1,"type":"integer"}`,
+		},
+		{
+			name:     "Shared definitions",
+			schema:   properties["restart"],
+			expected: `{"$ref":"#/$defs/RestartConfig"}`,
+		},
+		{
+			name:     "Custom JSON encoding",
+			schema:   schema["$defs"].(map[string]any)["IPConfig"],
+			expected: `{"additionalProperties":false,"properties":{"gateway":{"type":"string"},"interface":{"maxLength":15,"minLength":1,"type":"string"},"ip":{"type":"string"},"mtu":{"maximum":65535,"minimum":68,"type":"integer"}},"required":["ip"],"type":"object"}`,
+		},
+		{
+			name:     "Required fields",
+			schema:   schema["$defs"].(map[string]any)["Mount"].(map[string]any)["required"],
+			expected: `["mountPath","devicePath"]`,
+		},
+		{
+			name:     "Maps",
+			schema:   properties["extraEnv"],
+			expected: `{"additionalProperties":{"type":"string"},"type":"object"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.schema)
+			if err != nil {
+				t.Fatalf("Failed to marshal: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
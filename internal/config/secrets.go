@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SecretsDir is the tmpfs secret files are written to.
+	SecretsDir = "/run/secrets"
+
+	// DefaultSecretMode is the mode of secret files unless configured.
+	DefaultSecretMode os.FileMode = 0400
+
+	// RedactedValue replaces secret values wherever the configuration is
+	// shown.
+	RedactedValue = "[redacted]"
+)
+
+// SecretConfig is a credential handed to the application without going
+// through extraEnv. It is exposed as the environment variable Env, as the
+// file SecretsDir/File, or both. Only the application and its hooks get
+// secrets in their environment; commands run over the API do not.
+type SecretConfig struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Env   string `json:"env,omitempty"`
+	File  string `json:"file,omitempty"`
+	// Mode is the octal mode of the file, e.g. "0440". Defaults to 0400.
+	Mode string `json:"mode,omitempty"`
+	// Owner is the user[:group] owning the file. Defaults to the
+	// application's user.
+	Owner string `json:"owner,omitempty"`
+}
+
+// GetMode returns the mode of the secret's file.
+func (s SecretConfig) GetMode() os.FileMode {
+	mode, err := parseFileMode(s.Mode)
+	if err != nil || s.Mode == "" {
+		return DefaultSecretMode
+	}
+	return mode
+}
+
+// GetSecretEnvironment returns the secrets exposed as environment variables.
+func (c *RunConfig) GetSecretEnvironment() map[string]string {
+	var env map[string]string
+	for _, secret := range c.Secrets {
+		if secret.Env == "" {
+			continue
+		}
+		if env == nil {
+			env = make(map[string]string)
+		}
+		env[secret.Env] = secret.Value
+	}
+	return env
+}
+
+// GetSecretValues returns the value of every secret, for redaction.
+func (c *RunConfig) GetSecretValues() []string {
+	values := make([]string, 0, len(c.Secrets))
+	for _, secret := range c.Secrets {
+		values = append(values, secret.Value)
+	}
+	return values
+}
+
+// Redacted returns a copy of the configuration with secret values
+// replaced by RedactedValue.
+func (c *RunConfig) Redacted() *RunConfig {
+	redacted := *c
+	if len(c.Secrets) > 0 {
+		redacted.Secrets = make([]SecretConfig, len(c.Secrets))
+		for i, secret := range c.Secrets {
+			secret.Value = RedactedValue
+			redacted.Secrets[i] = secret
+		}
+	}
+	return &redacted
+}
+
+func (c *RunConfig) validateSecrets(p *problems) {
+	names := make(map[string]bool)
+	envs := make(map[string]bool)
+	files := make(map[string]bool)
+	for i, secret := range c.Secrets {
+		path := indexPath("secrets", i)
+		switch {
+		case secret.Name == "":
+			p.add(fieldPath(path, "name"), "is required")
+		case names[secret.Name]:
+			p.add(fieldPath(path, "name"), "duplicate name %s", secret.Name)
+		}
+		names[secret.Name] = true
+
+		if secret.Env == "" && secret.File == "" {
+			p.add(path, "must set env, file or both")
+		}
+		if secret.Env != "" {
+			switch {
+			case !isEnvName(secret.Env):
+				p.add(fieldPath(path, "env"), "invalid variable name %s", secret.Env)
+			case envs[secret.Env]:
+				p.add(fieldPath(path, "env"), "duplicate variable %s", secret.Env)
+			}
+			envs[secret.Env] = true
+		}
+		if secret.File != "" {
+			switch {
+			case secret.File == "." || secret.File == ".." || strings.Contains(secret.File, "/"):
+				p.add(fieldPath(path, "file"), "must be a file name, got %s", secret.File)
+			case files[secret.File]:
+				p.add(fieldPath(path, "file"), "duplicate file %s", secret.File)
+			}
+			files[secret.File] = true
+		}
+		if _, err := parseFileMode(secret.Mode); err != nil {
+			p.add(fieldPath(path, "mode"), "%v", err)
+		}
+	}
+}
+
+// parseFileMode parses an octal permission mode such as "0640". An empty
+// mode is zero.
+func parseFileMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid mode %s, expected octal permissions such as 0440", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// isEnvName reports whether s can be used as an environment variable name.
+func isEnvName(s string) bool {
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
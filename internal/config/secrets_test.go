@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunConfig_Secrets(t *testing.T) {
+	config := RunConfig{
+		ImageConfig: &ImageConfig{Cmd: []string{"/bin/app"}},
+		ExtraEnv:    map[string]string{"DB_PASSWORD": "from-extra-env"},
+		Secrets: []SecretConfig{
+			{Name: "db", Value: "hunter2", Env: "DB_PASSWORD", File: "db"},
+			{Name: "tls", Value: "key", File: "tls.key", Mode: "0440"},
+		},
+	}
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	expectedEnv := map[string]string{"DB_PASSWORD": "hunter2"}
+	if env := config.GetSecretEnvironment(); !reflect.DeepEqual(env, expectedEnv) {
+		t.Errorf("Expected secret env %v, got %v", expectedEnv, env)
+	}
+
+	app := config.GetProcesses()[0]
+	env := config.GetProcessEnvironment(app.Env, "")
+	if env[len(env)-1] != "DB_PASSWORD=hunter2" {
+		t.Errorf("Expected the application's secret to override extraEnv, got %v", env)
+	}
+
+	if mode := config.Secrets[0].GetMode(); mode != DefaultSecretMode {
+		t.Errorf("Expected default mode %o, got %o", DefaultSecretMode, mode)
+	}
+	if mode := config.Secrets[1].GetMode(); mode != os.FileMode(0440) {
+		t.Errorf("Expected mode 0440, got %o", mode)
+	}
+
+	expectedValues := []string{"hunter2", "key"}
+	if values := config.GetSecretValues(); !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("Expected values %v, got %v", expectedValues, values)
+	}
+}
+
+func TestRunConfig_ToJSON_RedactsSecrets(t *testing.T) {
+	config := RunConfig{
+		Secrets: []SecretConfig{
+			{Name: "db", Value: "hunter2", Env: "DB_PASSWORD"},
+		},
+	}
+
+	data, err := config.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	if strings.Contains(data, "hunter2") || !strings.Contains(data, RedactedValue) {
+		t.Errorf("Expected the secret value to be redacted, got %s", data)
+	}
+	if config.Secrets[0].Value != "hunter2" {
+		t.Error("Expected ToJSON to leave the configuration unchanged")
+	}
+}
+
+func TestRunConfig_Validate_Secrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		secrets  []SecretConfig
+		errorMsg string
+	}{
+		{
+			name:     "Missing name",
+			secrets:  []SecretConfig{{Env: "TOKEN"}},
+			errorMsg: "secrets[0].name: is required",
+		},
+		{
+			name: "Duplicate name",
+			secrets: []SecretConfig{
+				{Name: "token", Env: "TOKEN"},
+				{Name: "token", Env: "OTHER_TOKEN"},
+			},
+			errorMsg: "secrets[1].name: duplicate name token",
+		},
+		{
+			name:     "Not exposed",
+			secrets:  []SecretConfig{{Name: "token"}},
+			errorMsg: "secrets[0]: must set env, file or both",
+		},
+		{
+			name:     "Invalid variable name",
+			secrets:  []SecretConfig{{Name: "token", Env: "1TOKEN"}},
+			errorMsg: "secrets[0].env: invalid variable name 1TOKEN",
+		},
+		{
+			name: "Duplicate variable",
+			secrets: []SecretConfig{
+				{Name: "a", Env: "TOKEN"},
+				{Name: "b", Env: "TOKEN"},
+			},
+			errorMsg: "secrets[1].env: duplicate variable TOKEN",
+		},
+		{
+			name:     "File outside the secrets directory",
+			secrets:  []SecretConfig{{Name: "token", File: "../etc/token"}},
+			errorMsg: "secrets[0].file: must be a file name, got ../etc/token",
+		},
+		{
+			name: "Duplicate file",
+			secrets: []SecretConfig{
+				{Name: "a", File: "token"},
+				{Name: "b", File: "token"},
+			},
+			errorMsg: "secrets[1].file: duplicate file token",
+		},
+		{
+			name:     "Invalid mode",
+			secrets:  []SecretConfig{{Name: "token", File: "token", Mode: "rw-r-----"}},
+			errorMsg: "secrets[0].mode: invalid mode rw-r-----, expected octal permissions such as 0440",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := RunConfig{
+				ImageConfig: &ImageConfig{Cmd: []string{"/bin/app"}},
+				Secrets:     tt.secrets,
+			}
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShellSyntaxError reports malformed shell words. Pos is the 1-based
+// character position of the offending character.
+type ShellSyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ShellSyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// SplitShellWords splits s into words the way a POSIX shell does, without
+// running any of it. Single quotes preserve everything literally, double
+// quotes preserve everything but backslash escapes of $, `, ", \ and
+// newline, and an unquoted backslash escapes the next character.
+//
+// If lookup is not nil, $VAR and ${VAR} are replaced by lookup's value for
+// VAR, or by nothing if it is unset; single quotes and backslashes prevent
+// this as usual. Expanded values are not split into further words, and an
+// unquoted expansion to nothing is no word at all. If lookup is nil, $ has
+// no special meaning.
+func SplitShellWords(s string, lookup func(string) (string, bool)) ([]string, error) {
+	input := []rune(s)
+
+	var words []string
+	var word strings.Builder
+	// inWord distinguishes an empty quoted word such as "" from no word
+	inWord := false
+
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		case c == '\\':
+			if i+1 == len(input) {
+				return nil, &ShellSyntaxError{Pos: i + 1, Msg: "trailing backslash"}
+			}
+			i++
+			// an escaped newline continues the line
+			if input[i] != '\n' {
+				word.WriteRune(input[i])
+				inWord = true
+			}
+
+		case c == '\'':
+			end := indexRune(input, i+1, '\'')
+			if end < 0 {
+				return nil, &ShellSyntaxError{Pos: i + 1, Msg: "unterminated single quote"}
+			}
+			word.WriteString(string(input[i+1 : end]))
+			inWord = true
+			i = end
+
+		case c == '"':
+			end, err := readDoubleQuoted(input, i, &word, lookup)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i = end
+
+		case c == '$' && lookup != nil:
+			n := word.Len()
+			end, err := expandVariable(input, i, &word, lookup)
+			if err != nil {
+				return nil, err
+			}
+			if word.Len() > n {
+				inWord = true
+			}
+			i = end
+
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// readDoubleQuoted appends the contents of the double-quoted string starting
+// at input[start] to word and returns the index of the closing quote.
+func readDoubleQuoted(input []rune, start int, word *strings.Builder, lookup func(string) (string, bool)) (int, error) {
+	for i := start + 1; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == '"':
+			return i, nil
+
+		case c == '\\' && i+1 < len(input) && strings.ContainsRune("$`\"\\\n", input[i+1]):
+			i++
+			if input[i] != '\n' {
+				word.WriteRune(input[i])
+			}
+
+		case c == '$' && lookup != nil:
+			end, err := expandVariable(input, i, word, lookup)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+
+		default:
+			word.WriteRune(c)
+		}
+	}
+	return 0, &ShellSyntaxError{Pos: start + 1, Msg: "unterminated double quote"}
+}
+
+// expandVariable appends the value of the variable referenced at
+// input[start], which is a $, to word and returns the index of the last
+// character of the reference. A $ that does not start a variable name is
+// kept as is.
+func expandVariable(input []rune, start int, word *strings.Builder, lookup func(string) (string, bool)) (int, error) {
+	if start+1 < len(input) && input[start+1] == '{' {
+		end := indexRune(input, start+2, '}')
+		if end < 0 {
+			return 0, &ShellSyntaxError{Pos: start + 1, Msg: "unterminated ${"}
+		}
+		name := string(input[start+2 : end])
+		if !isShellName(name) {
+			return 0, &ShellSyntaxError{Pos: start + 1, Msg: fmt.Sprintf("bad substitution ${%s}", name)}
+		}
+		value, _ := lookup(name)
+		word.WriteString(value)
+		return end, nil
+	}
+
+	end := start + 1
+	for end < len(input) && isShellNameRune(input[end], end == start+1) {
+		end++
+	}
+	if end == start+1 {
+		word.WriteRune('$')
+		return start, nil
+	}
+	value, _ := lookup(string(input[start+1 : end]))
+	word.WriteString(value)
+	return end - 1, nil
+}
+
+func indexRune(input []rune, from int, r rune) int {
+	for i := from; i < len(input); i++ {
+		if input[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func isShellName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range []rune(name) {
+		if !isShellNameRune(r, i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isShellNameRune(r rune, first bool) bool {
+	switch {
+	case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		return true
+	case r >= '0' && r <= '9':
+		return !first
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	env := map[string]string{
+		"NAME":  "world",
+		"PORT":  "8080",
+		"SPACE": "a b",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expand   bool
+		expected []string
+	}{
+		{
+			name:     "Plain words",
+			input:    "  server --port 8080\t-v ",
+			expected: []string{"server", "--port", "8080", "-v"},
+		},
+		{
+			name:     "Double quotes",
+			input:    `sh -c "echo hello world"`,
+			expected: []string{"sh", "-c", "echo hello world"},
+		},
+		{
+			name:     "Single quotes are literal",
+			input:    `echo 'a "b" \c $NAME'`,
+			expand:   true,
+			expected: []string{"echo", `a "b" \c $NAME`},
+		},
+		{
+			name:     "Backslash escapes",
+			input:    `touch my\ file \"quoted\" back\\slash`,
+			expected: []string{"touch", "my file", `"quoted"`, `back\slash`},
+		},
+		{
+			name:     "Backslash inside double quotes",
+			input:    `echo "a\"b \$x \\ \n"`,
+			expected: []string{"echo", `a"b $x \ \n`},
+		},
+		{
+			name:     "Escaped newline continues the line",
+			input:    "echo one\\\ntwo",
+			expected: []string{"echo", "onetwo"},
+		},
+		{
+			name:     "Adjacent quoting joins into one word",
+			input:    `--name="my app"'s'`,
+			expected: []string{"--name=my apps"},
+		},
+		{
+			name:     "Empty quoted words are kept",
+			input:    `cmd "" ''`,
+			expected: []string{"cmd", "", ""},
+		},
+		{
+			name:     "No expansion without lookup",
+			input:    "echo $NAME ${PORT}",
+			expected: []string{"echo", "$NAME", "${PORT}"},
+		},
+		{
+			name:     "Expansion",
+			input:    `serve --port=$PORT "hello ${NAME}!" $UNSET end`,
+			expand:   true,
+			expected: []string{"serve", "--port=8080", "hello world!", "end"},
+		},
+		{
+			name:     "Quoted empty expansion is kept",
+			input:    `echo "$UNSET" x$UNSET`,
+			expand:   true,
+			expected: []string{"echo", "", "x"},
+		},
+		{
+			name:     "Expanded values are not split",
+			input:    "echo $SPACE",
+			expand:   true,
+			expected: []string{"echo", "a b"},
+		},
+		{
+			name:     "Dollar without a name is literal",
+			input:    `echo $ "$1" $-x`,
+			expand:   true,
+			expected: []string{"echo", "$", "$1", "$-x"},
+		},
+		{
+			name:     "Escaped dollar is not expanded",
+			input:    `echo \$NAME "\$NAME"`,
+			expand:   true,
+			expected: []string{"echo", "$NAME", "$NAME"},
+		},
+		{
+			name:     "Empty input",
+			input:    "   ",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l func(string) (string, bool)
+			if tt.expand {
+				l = lookup
+			}
+			words, err := SplitShellWords(tt.input, l)
+			if err != nil {
+				t.Fatalf("SplitShellWords failed: %v", err)
+			}
+			if !reflect.DeepEqual(words, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, words)
+			}
+		})
+	}
+}
+
+func TestSplitShellWords_Errors(t *testing.T) {
+	lookup := func(string) (string, bool) { return "", false }
+
+	tests := []struct {
+		name     string
+		input    string
+		errorMsg string
+		pos      int
+	}{
+		{
+			name:     "Unterminated double quote",
+			input:    `sh -c "echo hi`,
+			errorMsg: "unterminated double quote at position 7",
+			pos:      7,
+		},
+		{
+			name:     "Unterminated single quote",
+			input:    `echo 'it`,
+			errorMsg: "unterminated single quote at position 6",
+			pos:      6,
+		},
+		{
+			name:     "Trailing backslash",
+			input:    `echo hi\`,
+			errorMsg: "trailing backslash at position 8",
+			pos:      8,
+		},
+		{
+			name:     "Unterminated brace",
+			input:    `echo "${NAME"`,
+			errorMsg: "unterminated ${ at position 7",
+			pos:      7,
+		},
+		{
+			name:     "Bad substitution",
+			input:    `echo ${1x}`,
+			errorMsg: "bad substitution ${1x} at position 6",
+			pos:      6,
+		},
+		{
+			name:     "Positions count characters",
+			input:    `echo ünïcode "x`,
+			errorMsg: "unterminated double quote at position 14",
+			pos:      14,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SplitShellWords(tt.input, lookup)
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+			var syntaxErr *ShellSyntaxError
+			if !errors.As(err, &syntaxErr) || syntaxErr.Pos != tt.pos {
+				t.Errorf("Expected ShellSyntaxError at position %d, got %#v", tt.pos, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+const (
+	// CmdlineConfigParam is the kernel command line parameter naming a
+	// config file, e.g. init.config=/etc/run.json.
+	CmdlineConfigParam = "init.config"
+	// CmdlineConfigDeviceParam is the kernel command line parameter naming
+	// a block device holding the raw config JSON.
+	CmdlineConfigDeviceParam = "init.config_device"
+	// CmdlineConfigLenientParam set to 1 on the kernel command line makes
+	// unknown config fields a warning instead of an error.
+	CmdlineConfigLenientParam = "init.config_lenient"
+	// ConfigEnvVar is the environment variable naming a config file.
+	ConfigEnvVar = "INIT_CONFIG"
+	// DefaultConfigPath is where the config is looked for in the initramfs.
+	DefaultConfigPath = "/fly/run.json"
+
+	SourceCmdline = "cmdline"
+	SourceEnv     = "env"
+	SourceDevice  = "device"
+	SourceFile    = "file"
+	SourceVSock   = "vsock"
+)
+
+// Source describes where the configuration was loaded from.
+type Source struct {
+	Kind     string `json:"kind"`
+	Location string `json:"location"`
+}
+
+func (s Source) String() string {
+	return s.Kind + " " + s.Location
+}
+
+// Loader looks for the configuration in every supported source, in order of
+// precedence:
+//
+//  1. the file named by init.config= on the kernel command line
+//  2. the file named by the INIT_CONFIG environment variable
+//  3. the block device named by init.config_device= on the kernel command
+//     line
+//  4. /fly/run.json in the initramfs
+//  5. the host, over vsock
+//
+// Sources that are explicitly configured (1-3) must load; a missing or
+// broken config there is an error rather than a reason to fall through to
+// the next source. The initramfs file is skipped if it does not exist, and
+// the host is only asked once everything else came up empty.
+type Loader struct {
+	// Cmdline holds the kernel command line parameters.
+	Cmdline map[string]string
+	// Getenv looks up environment variables.
+	Getenv func(string) string
+	// FilePath is the initramfs config file, DefaultConfigPath if empty.
+	FilePath string
+	// DialHost connects to the host to receive the config over vsock. The
+	// host writes the config JSON and closes the connection. Nil disables
+	// the vsock source.
+	DialHost func() (io.ReadCloser, string, error)
+	// Lenient logs unknown fields instead of rejecting the config.
+	Lenient bool
+}
+
+// Load returns the configuration from the first source that has one.
+func (l *Loader) Load() (*RunConfig, Source, error) {
+	if path, ok := l.Cmdline[CmdlineConfigParam]; ok && path != "" {
+		return l.loadFile(Source{Kind: SourceCmdline, Location: path})
+	}
+	log.Printf("Config source %s (%s): not set", SourceCmdline, CmdlineConfigParam)
+
+	if l.Getenv != nil {
+		if path := l.Getenv(ConfigEnvVar); path != "" {
+			return l.loadFile(Source{Kind: SourceEnv, Location: path})
+		}
+	}
+	log.Printf("Config source %s (%s): not set", SourceEnv, ConfigEnvVar)
+
+	if device, ok := l.Cmdline[CmdlineConfigDeviceParam]; ok && device != "" {
+		return l.loadDevice(Source{Kind: SourceDevice, Location: device})
+	}
+	log.Printf("Config source %s (%s): not set", SourceDevice, CmdlineConfigDeviceParam)
+
+	path := l.FilePath
+	if path == "" {
+		path = DefaultConfigPath
+	}
+	if _, err := os.Stat(path); err == nil {
+		return l.loadFile(Source{Kind: SourceFile, Location: path})
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, Source{}, fmt.Errorf("config source %s %s: %v", SourceFile, path, err)
+	}
+	log.Printf("Config source %s (%s): not found", SourceFile, path)
+
+	if l.DialHost == nil {
+		log.Printf("Config source %s: not available", SourceVSock)
+		return nil, Source{}, fmt.Errorf("no configuration found in any source")
+	}
+	return l.loadHost()
+}
+
+func (l *Loader) loadFile(source Source) (*RunConfig, Source, error) {
+	log.Printf("Config source %s: loading", source)
+	data, err := os.ReadFile(source.Location)
+	if err != nil {
+		return nil, source, fmt.Errorf("config source %s: %v", source, err)
+	}
+	return l.parse(data, source)
+}
+
+func (l *Loader) loadDevice(source Source) (*RunConfig, Source, error) {
+	log.Printf("Config source %s: loading", source)
+	data, err := os.ReadFile(source.Location)
+	if err != nil {
+		return nil, source, fmt.Errorf("config source %s: %v", source, err)
+	}
+
+	// block devices are padded to their sector size
+	return l.parse(bytes.TrimRight(data, "\x00"), source)
+}
+
+func (l *Loader) loadHost() (*RunConfig, Source, error) {
+	log.Printf("Config source %s: asking the host", SourceVSock)
+	conn, location, err := l.DialHost()
+	source := Source{Kind: SourceVSock, Location: location}
+	if err != nil {
+		return nil, source, fmt.Errorf("config source %s: %v", SourceVSock, err)
+	}
+	defer conn.Close()
+
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, source, fmt.Errorf("config source %s: failed to receive config: %v", source, err)
+	}
+	return l.parse(data, source)
+}
+
+func (l *Loader) parse(data []byte, source Source) (*RunConfig, Source, error) {
+	config, warnings, err := parseConfig(data, !l.Lenient)
+	if err != nil {
+		return nil, source, fmt.Errorf("config source %s: %v", source, err)
+	}
+	for _, warning := range warnings {
+		log.Printf("Config source %s: warning: %s", source, warning)
+	}
+	return config, source, nil
+}
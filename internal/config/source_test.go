@@ -0,0 +1,200 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, dir, name, hostname string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	data := `{"hostname": "` + hostname + `", "imageConfig": {"cmd": ["/bin/app"]}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoader_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	cmdlinePath := writeConfig(t, dir, "cmdline.json", "from-cmdline")
+	envPath := writeConfig(t, dir, "env.json", "from-env")
+	filePath := writeConfig(t, dir, "run.json", "from-file")
+
+	// a raw config device is padded with zeros up to its sector size
+	devicePath := filepath.Join(dir, "vdb")
+	device := append([]byte(`{"hostname": "from-device"}`), make([]byte, 512)...)
+	if err := os.WriteFile(devicePath, device, 0644); err != nil {
+		t.Fatalf("Failed to write device: %v", err)
+	}
+
+	host := func() (io.ReadCloser, string, error) {
+		return io.NopCloser(strings.NewReader(`{"hostname": "from-host"}`)), "host:1001", nil
+	}
+
+	tests := []struct {
+		name     string
+		loader   Loader
+		hostname string
+		source   Source
+	}{
+		{
+			name: "Kernel command line wins",
+			loader: Loader{
+				Cmdline:  map[string]string{CmdlineConfigParam: cmdlinePath, CmdlineConfigDeviceParam: devicePath},
+				Getenv:   func(string) string { return envPath },
+				FilePath: filePath,
+				DialHost: host,
+			},
+			hostname: "from-cmdline",
+			source:   Source{Kind: SourceCmdline, Location: cmdlinePath},
+		},
+		{
+			name: "Environment before device",
+			loader: Loader{
+				Cmdline:  map[string]string{CmdlineConfigDeviceParam: devicePath},
+				Getenv:   func(string) string { return envPath },
+				FilePath: filePath,
+				DialHost: host,
+			},
+			hostname: "from-env",
+			source:   Source{Kind: SourceEnv, Location: envPath},
+		},
+		{
+			name: "Device before initramfs file",
+			loader: Loader{
+				Cmdline:  map[string]string{CmdlineConfigDeviceParam: devicePath},
+				Getenv:   func(string) string { return "" },
+				FilePath: filePath,
+				DialHost: host,
+			},
+			hostname: "from-device",
+			source:   Source{Kind: SourceDevice, Location: devicePath},
+		},
+		{
+			name: "Initramfs file before host",
+			loader: Loader{
+				FilePath: filePath,
+				DialHost: host,
+			},
+			hostname: "from-file",
+			source:   Source{Kind: SourceFile, Location: filePath},
+		},
+		{
+			name: "Host as last resort",
+			loader: Loader{
+				FilePath: filepath.Join(dir, "missing.json"),
+				DialHost: host,
+			},
+			hostname: "from-host",
+			source:   Source{Kind: SourceVSock, Location: "host:1001"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, source, err := tt.loader.Load()
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if config.Hostname != tt.hostname {
+				t.Errorf("Expected hostname %s, got %s", tt.hostname, config.Hostname)
+			}
+			if source != tt.source {
+				t.Errorf("Expected source %v, got %v", tt.source, source)
+			}
+		})
+	}
+}
+
+func TestLoader_Errors(t *testing.T) {
+	dir := t.TempDir()
+	filePath := writeConfig(t, dir, "run.json", "from-file")
+	invalidPath := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalidPath, []byte("{invalid"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		loader   Loader
+		errorMsg string
+	}{
+		{
+			name: "Explicit source does not fall through",
+			loader: Loader{
+				Cmdline:  map[string]string{CmdlineConfigParam: filepath.Join(dir, "missing.json")},
+				FilePath: filePath,
+			},
+			errorMsg: "config source cmdline " + filepath.Join(dir, "missing.json"),
+		},
+		{
+			name: "Broken initramfs file",
+			loader: Loader{
+				FilePath: invalidPath,
+			},
+			errorMsg: "config source file " + invalidPath + ": failed to parse config JSON",
+		},
+		{
+			name: "Nothing found",
+			loader: Loader{
+				FilePath: filepath.Join(dir, "missing.json"),
+			},
+			errorMsg: "no configuration found in any source",
+		},
+		{
+			name: "Host unreachable",
+			loader: Loader{
+				FilePath: filepath.Join(dir, "missing.json"),
+				DialHost: func() (io.ReadCloser, string, error) {
+					return nil, "host:1001", errors.New("connection refused")
+				},
+			},
+			errorMsg: "config source vsock: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.loader.Load()
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if !strings.HasPrefix(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error starting with '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoader_Lenient(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.json")
+	data := `{"hostname": "app", "hostnme": "typo"}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	strict := Loader{FilePath: path}
+	_, _, err := strict.Load()
+	if err == nil {
+		t.Fatal("Expected error but got nil")
+	}
+	expected := "config source file " + path + ": failed to parse config JSON: hostnme: unknown field"
+	if err.Error() != expected {
+		t.Errorf("Expected error message '%s', got '%s'", expected, err.Error())
+	}
+
+	lenient := Loader{FilePath: path, Lenient: true}
+	config, _, err := lenient.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if config.Hostname != "app" {
+		t.Errorf("Expected hostname app, got %s", config.Hostname)
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	NetworkWaitAbort = "abort"
+	NetworkWaitWarn  = "warn"
+
+	// DefaultNetworkWaitTimeout is how long init waits for the network
+	// unless configured.
+	DefaultNetworkWaitTimeout = 30 * time.Second
+)
+
+// NetworkWaitConfig holds the application back until the network is
+// ready: the configured IPv6 addresses are past duplicate address
+// detection, the gateways answer ARP or neighbor discovery and, if
+// Address is set, a TCP connection to that host:port succeeds. Timeout is
+// in seconds. OnTimeout is "abort", the default, to fail the boot or
+// "warn" to start the application anyway.
+type NetworkWaitConfig struct {
+	Timeout   int    `json:"timeout,omitempty"`
+	Address   string `json:"address,omitempty"`
+	OnTimeout string `json:"onTimeout,omitempty"`
+}
+
+// GetTimeout returns how long to wait for the network in total.
+func (w NetworkWaitConfig) GetTimeout() time.Duration {
+	if w.Timeout == 0 {
+		return DefaultNetworkWaitTimeout
+	}
+	return time.Duration(w.Timeout) * time.Second
+}
+
+// GetOnTimeout returns what to do when the network is not ready in time.
+func (w NetworkWaitConfig) GetOnTimeout() string {
+	if w.OnTimeout == "" {
+		return NetworkWaitAbort
+	}
+	return w.OnTimeout
+}
+
+func (w NetworkWaitConfig) validate(path string, p *problems) {
+	if w.Timeout < 0 {
+		p.add(fieldPath(path, "timeout"), "must not be negative")
+	}
+	if w.Address != "" {
+		host, port, err := net.SplitHostPort(w.Address)
+		if n, portErr := strconv.Atoi(port); err != nil || host == "" || portErr != nil || n < 1 || n > 65535 {
+			p.add(fieldPath(path, "address"), "invalid address %s, expected host:port", w.Address)
+		}
+	}
+	switch w.OnTimeout {
+	case "", NetworkWaitAbort, NetworkWaitWarn:
+	default:
+		p.add(fieldPath(path, "onTimeout"), "unknown timeout policy %s", w.OnTimeout)
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNetworkWaitConfig_Defaults(t *testing.T) {
+	wait := NetworkWaitConfig{}
+	if wait.GetTimeout() != DefaultNetworkWaitTimeout {
+		t.Errorf("Expected timeout %s, got %s", DefaultNetworkWaitTimeout, wait.GetTimeout())
+	}
+	if wait.GetOnTimeout() != NetworkWaitAbort {
+		t.Errorf("Expected on timeout %s, got %s", NetworkWaitAbort, wait.GetOnTimeout())
+	}
+
+	wait = NetworkWaitConfig{Timeout: 5, OnTimeout: NetworkWaitWarn}
+	if wait.GetTimeout() != 5*time.Second {
+		t.Errorf("Expected timeout 5s, got %s", wait.GetTimeout())
+	}
+	if wait.GetOnTimeout() != NetworkWaitWarn {
+		t.Errorf("Expected on timeout %s, got %s", NetworkWaitWarn, wait.GetOnTimeout())
+	}
+}
+
+func TestRunConfig_Validate_WaitForNetwork(t *testing.T) {
+	tests := []struct {
+		name     string
+		wait     NetworkWaitConfig
+		errorMsg string
+	}{
+		{
+			name:     "Valid",
+			wait:     NetworkWaitConfig{Timeout: 10, Address: "db.internal:5432", OnTimeout: NetworkWaitWarn},
+			errorMsg: "",
+		},
+		{
+			name:     "Negative timeout",
+			wait:     NetworkWaitConfig{Timeout: -1},
+			errorMsg: "waitForNetwork.timeout: must not be negative",
+		},
+		{
+			name:     "Address without port",
+			wait:     NetworkWaitConfig{Address: "db.internal"},
+			errorMsg: "waitForNetwork.address: invalid address db.internal, expected host:port",
+		},
+		{
+			name:     "Address with invalid port",
+			wait:     NetworkWaitConfig{Address: "[fdaa::3]:70000"},
+			errorMsg: "waitForNetwork.address: invalid address [fdaa::3]:70000, expected host:port",
+		},
+		{
+			name:     "Unknown timeout policy",
+			wait:     NetworkWaitConfig{OnTimeout: "retry"},
+			errorMsg: "waitForNetwork.onTimeout: unknown timeout policy retry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := RunConfig{ImageConfig: &ImageConfig{Cmd: []string{"/bin/app"}}, WaitForNetwork: &tt.wait}
+			err := config.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
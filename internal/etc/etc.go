@@ -0,0 +1,148 @@
+package etc
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+)
+
+const (
+	ResolvPath = "/etc/resolv.conf"
+	HostsPath  = "/etc/hosts"
+
+	header     = "# Generated by init from run.json"
+	hostsBegin = "# BEGIN generated by init"
+	hostsEnd   = "# END generated by init"
+)
+
+// hostnameFallbackIP is what the hostname resolves to when no address is
+// configured, as on Debian.
+var hostnameFallbackIP = net.IPv4(127, 0, 1, 1)
+
+// RenderResolv renders resolv.conf from resolv. If existing is not empty
+// the configured entries are merged into it: the configured nameservers
+// and search domains come first, options override the existing ones of
+// the same name and every other line is kept.
+func RenderResolv(resolv *config.EtcResolv, existing []byte) []byte {
+	nameservers := appendNew(nil, resolv.Nameservers...)
+	search := appendNew(nil, resolv.Search...)
+	options := appendNew(nil, resolv.Options...)
+
+	configured := make(map[string]bool)
+	for _, option := range resolv.Options {
+		name, _, _ := strings.Cut(option, ":")
+		configured[name] = true
+	}
+
+	var other []string
+	for _, line := range strings.Split(string(existing), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || line == header {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			nameservers = appendNew(nameservers, fields[1:]...)
+		case "search":
+			search = appendNew(search, fields[1:]...)
+		case "options":
+			for _, option := range fields[1:] {
+				if name, _, _ := strings.Cut(option, ":"); !configured[name] {
+					options = appendNew(options, option)
+				}
+			}
+		default:
+			other = append(other, line)
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, header)
+	for _, line := range other {
+		fmt.Fprintln(&b, line)
+	}
+	for _, nameserver := range nameservers {
+		fmt.Fprintf(&b, "nameserver %s\n", nameserver)
+	}
+	if len(search) > 0 {
+		fmt.Fprintf(&b, "search %s\n", strings.Join(search, " "))
+	}
+	if len(options) > 0 {
+		fmt.Fprintf(&b, "options %s\n", strings.Join(options, " "))
+	}
+	return b.Bytes()
+}
+
+// RenderHosts renders /etc/hosts with the localhost entries, the hostname
+// mapped to the primary address and the configured hosts. If existing is
+// not empty the generated entries are put in a marked block ahead of it,
+// so they take precedence, replacing the block of a previous boot.
+func RenderHosts(cfg *config.RunConfig, existing []byte) []byte {
+	var entries bytes.Buffer
+	if cfg.Hostname != "" {
+		ip := cfg.GetPrimaryIP()
+		if ip == nil {
+			ip = hostnameFallbackIP
+		}
+		fmt.Fprintf(&entries, "%s\t%s\n", ip, cfg.Hostname)
+	}
+	for _, host := range cfg.EtcHosts {
+		if host.Description != "" {
+			fmt.Fprintf(&entries, "# %s\n", host.Description)
+		}
+		fmt.Fprintf(&entries, "%s\t%s\n", host.IP, host.Host)
+	}
+
+	existing = stripHostsBlock(existing)
+
+	var b bytes.Buffer
+	if len(bytes.TrimSpace(existing)) == 0 {
+		fmt.Fprintln(&b, header)
+		fmt.Fprintln(&b, "127.0.0.1\tlocalhost")
+		fmt.Fprintln(&b, "::1\tlocalhost ip6-localhost ip6-loopback")
+		b.Write(entries.Bytes())
+		return b.Bytes()
+	}
+
+	fmt.Fprintln(&b, hostsBegin)
+	b.Write(entries.Bytes())
+	fmt.Fprintln(&b, hostsEnd)
+	b.Write(existing)
+	if !bytes.HasSuffix(existing, []byte("\n")) {
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// stripHostsBlock removes the block generated by RenderHosts from hosts.
+func stripHostsBlock(hosts []byte) []byte {
+	begin := bytes.Index(hosts, []byte(hostsBegin+"\n"))
+	if begin < 0 {
+		return hosts
+	}
+	end := bytes.Index(hosts[begin:], []byte(hostsEnd+"\n"))
+	if end < 0 {
+		return hosts
+	}
+	end += begin + len(hostsEnd) + 1
+
+	stripped := append([]byte{}, hosts[:begin]...)
+	return append(stripped, hosts[end:]...)
+}
+
+// appendNew appends the values that are not yet in list.
+func appendNew(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, v := range list {
+			found = found || v == value
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
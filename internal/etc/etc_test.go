@@ -0,0 +1,271 @@
+package etc
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/system"
+)
+
+func TestRenderResolv(t *testing.T) {
+	resolv := &config.EtcResolv{
+		Nameservers: []string{"fdaa::3", "8.8.8.8"},
+		Search:      []string{"internal"},
+		Options:     []string{"ndots:2", "rotate"},
+	}
+
+	tests := []struct {
+		name     string
+		existing string
+		expected string
+	}{
+		{
+			name: "Replace",
+			expected: header + "\n" +
+				"nameserver fdaa::3\n" +
+				"nameserver 8.8.8.8\n" +
+				"search internal\n" +
+				"options ndots:2 rotate\n",
+		},
+		{
+			name: "Merge",
+			existing: "# from the image\n" +
+				"nameserver 1.1.1.1\n" +
+				"nameserver 8.8.8.8\n" +
+				"search example.com internal\n" +
+				"options ndots:5 edns0\n" +
+				"sortlist 10.0.0.0/255.0.0.0\n",
+			expected: header + "\n" +
+				"# from the image\n" +
+				"sortlist 10.0.0.0/255.0.0.0\n" +
+				"nameserver fdaa::3\n" +
+				"nameserver 8.8.8.8\n" +
+				"nameserver 1.1.1.1\n" +
+				"search internal example.com\n" +
+				"options ndots:2 rotate edns0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(RenderResolv(resolv, []byte(tt.existing))); got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+
+	// merging again keeps the file as it is
+	merged := RenderResolv(resolv, []byte(tests[1].existing))
+	if got := string(RenderResolv(resolv, merged)); got != string(merged) {
+		t.Errorf("Expected merging to be idempotent, got:\n%s", got)
+	}
+}
+
+func TestRenderHosts(t *testing.T) {
+	_, ipNet, _ := net.ParseCIDR("172.19.0.0/24")
+	ipNet.IP = net.ParseIP("172.19.0.2").To4()
+	cfg := &config.RunConfig{
+		Hostname:  "app",
+		IPConfigs: []config.IPConfig{{IP: ipNet}},
+		EtcHosts: []config.EtcHost{
+			{Host: "db", IP: "172.19.0.3", Description: "primary database"},
+			{Host: "cache", IP: "fdaa::4"},
+		},
+	}
+	entries := "172.19.0.2\tapp\n" +
+		"# primary database\n" +
+		"172.19.0.3\tdb\n" +
+		"fdaa::4\tcache\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		expected string
+	}{
+		{
+			name: "Replace",
+			expected: header + "\n" +
+				"127.0.0.1\tlocalhost\n" +
+				"::1\tlocalhost ip6-localhost ip6-loopback\n" +
+				entries,
+		},
+		{
+			name:     "Merge",
+			existing: "127.0.0.1 localhost\n10.0.0.1 gateway",
+			expected: hostsBegin + "\n" + entries + hostsEnd + "\n" +
+				"127.0.0.1 localhost\n10.0.0.1 gateway\n",
+		},
+		{
+			name: "Merge replaces a previous block",
+			existing: hostsBegin + "\n172.19.0.9\told\n" + hostsEnd + "\n" +
+				"127.0.0.1 localhost\n",
+			expected: hostsBegin + "\n" + entries + hostsEnd + "\n" +
+				"127.0.0.1 localhost\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(RenderHosts(cfg, []byte(tt.existing))); got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRenderHosts_NoAddress(t *testing.T) {
+	got := string(RenderHosts(&config.RunConfig{Hostname: "app"}, nil))
+	expected := header + "\n" +
+		"127.0.0.1\tlocalhost\n" +
+		"::1\tlocalhost ip6-localhost ip6-loopback\n" +
+		"127.0.1.1\tapp\n"
+	if got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	resolvPath := filepath.Join(dir, "resolv.conf")
+	hostsPath := filepath.Join(dir, "hosts")
+
+	// resolv.conf is often a symlink to a stub that does not exist
+	if err := os.Symlink("../run/systemd/resolve/stub-resolv.conf", resolvPath); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.WriteFile(hostsPath, []byte("10.0.0.1 gateway\n"), 0644); err != nil {
+		t.Fatalf("Failed to write hosts: %v", err)
+	}
+
+	cfg := &config.RunConfig{
+		Hostname:  "app",
+		EtcResolv: &config.EtcResolv{Nameservers: []string{"8.8.8.8"}},
+		EtcMode:   config.EtcModeMerge,
+	}
+	if err := write(cfg, resolvPath, hostsPath); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	info, err := os.Lstat(resolvPath)
+	if err != nil {
+		t.Fatalf("Failed to stat resolv.conf: %v", err)
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm() != 0644 {
+		t.Errorf("Expected the symlink to be replaced by a 0644 file, got %s", info.Mode())
+	}
+	data, _ := os.ReadFile(resolvPath)
+	if expected := header + "\nnameserver 8.8.8.8\n"; string(data) != expected {
+		t.Errorf("Expected resolv.conf:\n%s\ngot:\n%s", expected, data)
+	}
+
+	data, _ = os.ReadFile(hostsPath)
+	if expected := hostsBegin + "\n127.0.1.1\tapp\n" + hostsEnd + "\n10.0.0.1 gateway\n"; string(data) != expected {
+		t.Errorf("Expected hosts:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestWrite_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	resolvPath := filepath.Join(dir, "resolv.conf")
+	hostsPath := filepath.Join(dir, "hosts")
+
+	stagingDir = filepath.Join(t.TempDir(), "init-etc")
+	replace = func(path string, data []byte) error {
+		if filepath.Dir(path) == dir {
+			return &os.PathError{Op: "open", Path: path, Err: syscall.EROFS}
+		}
+		return replaceFile(path, data)
+	}
+	mounted := map[string]string{}
+	bindMount = func(source, target string) error {
+		mounted[target] = source
+		return nil
+	}
+	t.Cleanup(func() {
+		stagingDir, replace, bindMount = StagingDir, replaceFile, system.BindMount
+	})
+
+	cfg := &config.RunConfig{
+		Hostname:  "app",
+		EtcResolv: &config.EtcResolv{Nameservers: []string{"8.8.8.8"}},
+	}
+	if err := write(cfg, resolvPath, hostsPath); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	for _, path := range []string{resolvPath, hostsPath} {
+		source := filepath.Join(stagingDir, filepath.Base(path))
+		if mounted[path] != source {
+			t.Errorf("Expected %s to be bind mounted from %s, got %q", path, source, mounted[path])
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(stagingDir, "resolv.conf"))
+	if expected := header + "\nnameserver 8.8.8.8\n"; string(data) != expected {
+		t.Errorf("Expected staged resolv.conf:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestWrite_ReadOnlyDanglingSymlink(t *testing.T) {
+	dir := t.TempDir()
+	resolvPath := filepath.Join(dir, "resolv.conf")
+	hostsPath := filepath.Join(dir, "hosts")
+	if err := os.Symlink("../run/systemd/resolve/stub-resolv.conf", resolvPath); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.WriteFile(hostsPath, nil, 0644); err != nil {
+		t.Fatalf("Failed to write hosts: %v", err)
+	}
+
+	stagingDir = filepath.Join(t.TempDir(), "init-etc")
+	replace = func(path string, data []byte) error {
+		if filepath.Dir(path) == dir {
+			return &os.PathError{Op: "open", Path: path, Err: syscall.EROFS}
+		}
+		return replaceFile(path, data)
+	}
+	mounted := map[string]string{}
+	bindMount = func(source, target string) error {
+		if _, err := os.Stat(target); err != nil {
+			return err
+		}
+		mounted[target] = source
+		return nil
+	}
+	t.Cleanup(func() {
+		stagingDir, replace, bindMount = StagingDir, replaceFile, system.BindMount
+	})
+
+	cfg := &config.RunConfig{
+		Hostname:  "app",
+		EtcResolv: &config.EtcResolv{Nameservers: []string{"8.8.8.8"}},
+	}
+	if err := write(cfg, resolvPath, hostsPath); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	if _, ok := mounted[resolvPath]; ok {
+		t.Error("Expected the dangling resolv.conf symlink to be skipped")
+	}
+	if _, ok := mounted[hostsPath]; !ok {
+		t.Error("Expected hosts to be mounted over")
+	}
+}
+
+func TestWrite_Nothing(t *testing.T) {
+	dir := t.TempDir()
+	resolvPath := filepath.Join(dir, "resolv.conf")
+	hostsPath := filepath.Join(dir, "hosts")
+
+	if err := write(&config.RunConfig{}, resolvPath, hostsPath); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	for _, path := range []string{resolvPath, hostsPath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be written", path)
+		}
+	}
+}
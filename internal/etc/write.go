@@ -0,0 +1,128 @@
+package etc
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/system"
+)
+
+// StagingDir is where generated files are written when /etc is read-only.
+// It lives on the /dev/shm tmpfs MountEssential mounts, so it can be
+// created on a read-only root. The files are bind mounted over the
+// image's files from there.
+const StagingDir = "/dev/shm/init-etc"
+
+// Replaced in tests, which cannot make a directory read-only.
+var (
+	stagingDir = StagingDir
+	replace    = replaceFile
+	bindMount  = system.BindMount
+)
+
+// Write generates /etc/resolv.conf when cfg has an etcResolv section and
+// /etc/hosts when it has a hostname or etcHosts, merging them with the
+// image's files if cfg asks for it. A symlink at either path, e.g. to a
+// systemd-resolved stub, is replaced by the file.
+func Write(cfg *config.RunConfig) error {
+	return write(cfg, ResolvPath, HostsPath)
+}
+
+func write(cfg *config.RunConfig, resolvPath, hostsPath string) error {
+	merge := cfg.GetEtcMode() == config.EtcModeMerge
+
+	var errs []error
+	if cfg.EtcResolv != nil {
+		existing, err := readExisting(resolvPath, merge)
+		if err == nil {
+			err = install(resolvPath, RenderResolv(cfg.EtcResolv, existing))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to write %s: %w", resolvPath, err))
+		}
+	}
+
+	if cfg.Hostname != "" || len(cfg.EtcHosts) > 0 {
+		existing, err := readExisting(hostsPath, merge)
+		if err == nil {
+			err = install(hostsPath, RenderHosts(cfg, existing))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to write %s: %w", hostsPath, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readExisting returns the content of the image's file at path when
+// merging. A missing file, or a symlink to one, is empty.
+func readExisting(path string, merge bool) ([]byte, error) {
+	if !merge {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// install writes data to path, falling back to a bind mount from
+// StagingDir when the file system is read-only. A dangling symlink at path
+// cannot be mounted over on a read-only file system, so it is left alone.
+func install(path string, data []byte) error {
+	err := replace(path, data)
+	if !errors.Is(err, syscall.EROFS) {
+		if err == nil {
+			log.Printf("Generated %s", path)
+		}
+		return err
+	}
+
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			log.Printf("Not generating %s: the file system is read-only and it is a symlink to a missing file", path)
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return err
+	}
+	source := filepath.Join(stagingDir, filepath.Base(path))
+	if err := replace(source, data); err != nil {
+		return err
+	}
+	if err := bindMount(source, path); err != nil {
+		return err
+	}
+	log.Printf("Generated %s, mounted over it because the file system is read-only", path)
+	return nil
+}
+
+// replaceFile atomically replaces path with a regular file containing
+// data. A symlink at path is replaced rather than followed.
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -2,9 +2,11 @@ package exec
 
 import (
 	"bytes"
+	"io"
 	"os/exec"
 	"sync"
-	"syscall"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
 )
 
 type ExecRequest struct {
@@ -18,33 +20,53 @@ type ExecResponse struct {
 	Stderr     []byte `json:"stderr"`
 }
 
-func ExecuteCommand(req ExecRequest, envs map[string]string, waitPidMutex *sync.Mutex) (ExecResponse, error) {
+func ExecuteCommand(req ExecRequest, env []string, r *reaper.Reaper) (ExecResponse, error) {
 	cmd := exec.Command(req.Cmd[0], req.Cmd[1:]...)
-	cmd.Env = envToSlice(envs)
-	waitPidMutex.Lock()
-	defer waitPidMutex.Unlock()
+	// a nil Env would hand the command init's own environment
+	cmd.Env = append([]string{}, env...)
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	// the reaper owns the exit status, so output is read through pipes
+	// rather than collected by cmd.Wait
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return ExecResponse{}, err
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		stdoutPipe.Close()
+		return ExecResponse{}, err
+	}
+	defer stdoutPipe.Close()
+	defer stderrPipe.Close()
+
+	// a command that cannot be started is reported without an exit code
+	// or signal rather than as an error
+	process, err := r.Start(cmd)
+	if err != nil {
+		return ExecResponse{}, nil
+	}
 
-	// no handling error or early return
-	// we want to capture the exit code ,signal , output
-	_ = cmd.Run()
+	var stdout, stderr bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(&stdout, stdoutPipe)
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(&stderr, stderrPipe)
+	}()
+	wg.Wait()
 
 	var exitCode, exitSignal *int
-	if cmd.ProcessState != nil {
-		status := cmd.ProcessState.Sys().(syscall.WaitStatus)
-		if status.Signaled() {
-			signal := int(status.Signal())
-			exitSignal = &signal
-		} else if status.Exited() {
-			code := status.ExitStatus()
-			exitCode = &code
-		} else {
-			exitCode = nil
-			exitSignal = nil
-		}
+	status := process.Wait()
+	if status.Signaled() {
+		signal := int(status.Signal())
+		exitSignal = &signal
+	} else if status.Exited() {
+		code := status.ExitStatus()
+		exitCode = &code
 	}
 
 	return ExecResponse{
@@ -55,11 +77,3 @@ func ExecuteCommand(req ExecRequest, envs map[string]string, waitPidMutex *sync.
 	}, nil
 
 }
-
-func envToSlice(envs map[string]string) []string {
-	envSlice := make([]string, 0, len(envs))
-	for key, value := range envs {
-		envSlice = append(envSlice, key+"="+value)
-	}
-	return envSlice
-}
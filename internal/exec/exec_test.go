@@ -1,18 +1,20 @@
 package exec
 
 import (
-	"sync"
+	"strings"
 	"testing"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper/reapertest"
 )
 
 func TestExecuteCommand_Success(t *testing.T) {
 	req := ExecRequest{
 		Cmd: []string{"echo", "hello world"},
 	}
-	envs := map[string]string{}
-	mutex := &sync.Mutex{}
+	envs := []string{}
+	r := reapertest.Reaper()
 
-	response, err := ExecuteCommand(req, envs, mutex)
+	response, err := ExecuteCommand(req, envs, r)
 
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
@@ -40,10 +42,10 @@ func TestExecuteCommand_Failure(t *testing.T) {
 	req := ExecRequest{
 		Cmd: []string{"ls", "/nonexistent-directory"},
 	}
-	envs := map[string]string{}
-	mutex := &sync.Mutex{}
+	envs := []string{}
+	r := reapertest.Reaper()
 
-	response, err := ExecuteCommand(req, envs, mutex)
+	response, err := ExecuteCommand(req, envs, r)
 
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
@@ -70,12 +72,10 @@ func TestExecuteCommand_WithEnvironment(t *testing.T) {
 	req := ExecRequest{
 		Cmd: []string{"sh", "-c", "echo $TEST_VAR"},
 	}
-	envs := map[string]string{
-		"TEST_VAR": "test_value",
-	}
-	mutex := &sync.Mutex{}
+	envs := []string{"TEST_VAR=test_value"}
+	r := reapertest.Reaper()
 
-	response, err := ExecuteCommand(req, envs, mutex)
+	response, err := ExecuteCommand(req, envs, r)
 
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
@@ -95,10 +95,10 @@ func TestExecuteCommand_StderrOutput(t *testing.T) {
 	req := ExecRequest{
 		Cmd: []string{"sh", "-c", "echo 'error message' >&2"},
 	}
-	envs := map[string]string{}
-	mutex := &sync.Mutex{}
+	envs := []string{}
+	r := reapertest.Reaper()
 
-	response, err := ExecuteCommand(req, envs, mutex)
+	response, err := ExecuteCommand(req, envs, r)
 
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
@@ -122,8 +122,8 @@ func TestExecuteCommand_EmptyCommand(t *testing.T) {
 	req := ExecRequest{
 		Cmd: []string{},
 	}
-	envs := map[string]string{}
-	mutex := &sync.Mutex{}
+	envs := []string{}
+	r := reapertest.Reaper()
 
 	// should panic or return an error as no command is provided
 	defer func() {
@@ -132,23 +132,23 @@ func TestExecuteCommand_EmptyCommand(t *testing.T) {
 		}
 	}()
 
-	ExecuteCommand(req, envs, mutex)
+	ExecuteCommand(req, envs, r)
 }
 
-func TestExecuteCommand_MutexSerialization(t *testing.T) {
+func TestExecuteCommand_Concurrent(t *testing.T) {
 	req := ExecRequest{
 		Cmd: []string{"echo", "test"},
 	}
-	envs := map[string]string{}
-	mutex := &sync.Mutex{}
+	envs := []string{}
+	r := reapertest.Reaper()
 
-	// run multiple commands concurrently to test mutex
+	// run multiple commands concurrently, each waiting on its own exit status
 	results := make(chan ExecResponse, 3)
 	errors := make(chan error, 3)
 
 	for i := 0; i < 3; i++ {
 		go func() {
-			response, err := ExecuteCommand(req, envs, mutex)
+			response, err := ExecuteCommand(req, envs, r)
 			results <- response
 			errors <- err
 		}()
@@ -173,65 +173,14 @@ func TestExecuteCommand_MutexSerialization(t *testing.T) {
 	}
 }
 
-func TestEnvToSlice(t *testing.T) {
-	tests := []struct {
-		name     string
-		envs     map[string]string
-		expected []string
-	}{
-		{
-			name:     "empty environment",
-			envs:     map[string]string{},
-			expected: []string{},
-		},
-		{
-			name: "single environment variable",
-			envs: map[string]string{
-				"KEY": "value",
-			},
-			expected: []string{"KEY=value"},
-		},
-		{
-			name: "multiple environment variables",
-			envs: map[string]string{
-				"PATH": "/bin:/usr/bin",
-				"HOME": "/home/user",
-			},
-			expected: []string{"PATH=/bin:/usr/bin", "HOME=/home/user"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := envToSlice(tt.envs)
-
-			if len(result) != len(tt.expected) {
-				t.Errorf("Expected %d environment variables, got %d", len(tt.expected), len(result))
-				return
-			}
-
-			resultMap := make(map[string]bool)
-			for _, env := range result {
-				resultMap[env] = true
-			}
-
-			for _, expected := range tt.expected {
-				if !resultMap[expected] {
-					t.Errorf("Expected environment variable '%s' not found in result", expected)
-				}
-			}
-		})
-	}
-}
-
 func TestExecuteCommand_WithMultipleArgs(t *testing.T) {
 	req := ExecRequest{
 		Cmd: []string{"sh", "-c", "echo $1 $2", "_", "hello", "world"},
 	}
-	envs := map[string]string{}
-	mutex := &sync.Mutex{}
+	envs := []string{}
+	r := reapertest.Reaper()
 
-	response, err := ExecuteCommand(req, envs, mutex)
+	response, err := ExecuteCommand(req, envs, r)
 
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
@@ -247,3 +196,43 @@ func TestExecuteCommand_WithMultipleArgs(t *testing.T) {
 	}
 }
 
+func TestExecuteCommand_LongRunningDoesNotBlock(t *testing.T) {
+	r := reapertest.Reaper()
+
+	slow := make(chan struct{})
+	go func() {
+		ExecuteCommand(ExecRequest{Cmd: []string{"sleep", "2"}}, []string{}, r)
+		close(slow)
+	}()
+
+	response, err := ExecuteCommand(ExecRequest{Cmd: []string{"echo", "fast"}}, []string{}, r)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	select {
+	case <-slow:
+		t.Error("Expected fast command to finish before the slow one")
+	default:
+	}
+
+	if string(response.Stdout) != "fast\n" {
+		t.Errorf("Expected stdout 'fast\\n', got '%s'", string(response.Stdout))
+	}
+	<-slow
+}
+
+func TestExecuteCommand_EnvironmentOrder(t *testing.T) {
+	r := reapertest.Reaper()
+	env := []string{"TERM=linux", "HOME=/", "PATH=/bin:/usr/bin", "LANG=C"}
+
+	response, err := ExecuteCommand(ExecRequest{Cmd: []string{"env"}}, env, r)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := strings.Join(env, "\n") + "\n"
+	if string(response.Stdout) != expected {
+		t.Errorf("Expected stdout '%s', got '%s'", expected, string(response.Stdout))
+	}
+}
@@ -0,0 +1,87 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/user"
+)
+
+// Write writes every file in files. A file that fails does not stop the
+// others from being written; the returned error lists each failure.
+func Write(files []config.FileConfig) error {
+	var errs []error
+	for _, file := range files {
+		written, err := write(file)
+		switch {
+		case err != nil:
+			log.Printf("Failed to write file %s: %v", file.Path, err)
+			errs = append(errs, fmt.Errorf("file %s: %w", file.Path, err))
+		case written:
+			log.Printf("Wrote file %s", file.Path)
+		default:
+			log.Printf("Skipped file %s, it already exists", file.Path)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// write writes a single file and reports whether it did, which it does
+// not if the file exists and may not be overwritten.
+func write(file config.FileConfig) (bool, error) {
+	if _, err := os.Lstat(file.Path); err == nil {
+		switch file.GetOverwrite() {
+		case config.FileOverwriteSkip:
+			return false, nil
+		case config.FileOverwriteFail:
+			return false, fmt.Errorf("already exists")
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return false, fmt.Errorf("invalid content: %w", err)
+	}
+	owner, err := user.Lookup(file.Owner)
+	if err != nil {
+		return false, err
+	}
+
+	dir := filepath.Dir(file.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, err
+	}
+
+	// write next to the file and rename it into place, so the application
+	// never sees a partial file and a symlink at the path is replaced
+	// rather than followed
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(file.Path)+".*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := tmp.Write(content); err != nil {
+		return false, err
+	}
+	if err := tmp.Chown(int(owner.Uid), int(owner.Gid)); err != nil {
+		return false, err
+	}
+	if err := tmp.Chmod(file.GetMode()); err != nil {
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp.Name(), file.Path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
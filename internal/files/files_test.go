@@ -0,0 +1,148 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+)
+
+func currentOwner() string {
+	return fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	files := []config.FileConfig{
+		{Path: filepath.Join(dir, "etc/nginx/conf.d/app.conf"), Content: "listen 8080;\n", Owner: currentOwner()},
+		{Path: filepath.Join(dir, ".env"), Content: "S0VZPXZhbHVl", Encoding: config.FileEncodingBase64, Mode: "0600", Owner: currentOwner()},
+	}
+
+	if err := Write(files); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	tests := []struct {
+		path    string
+		content string
+		mode    os.FileMode
+	}{
+		{path: files[0].Path, content: "listen 8080;\n", mode: 0644},
+		{path: files[1].Path, content: "KEY=value", mode: 0600},
+	}
+	for _, tt := range tests {
+		info, err := os.Stat(tt.path)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", tt.path, err)
+		}
+		if info.Mode().Perm() != tt.mode {
+			t.Errorf("Expected %s to have mode %o, got %o", tt.path, tt.mode, info.Mode().Perm())
+		}
+		data, err := os.ReadFile(tt.path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", tt.path, err)
+		}
+		if string(data) != tt.content {
+			t.Errorf("Expected %s to contain %q, got %q", tt.path, tt.content, data)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected no temporary files to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestWrite_Overwrite(t *testing.T) {
+	tests := []struct {
+		overwrite string
+		expected  string
+		errorMsg  string
+	}{
+		{overwrite: "", expected: "new"},
+		{overwrite: config.FileOverwriteReplace, expected: "new"},
+		{overwrite: config.FileOverwriteSkip, expected: "old"},
+		{overwrite: config.FileOverwriteFail, expected: "old", errorMsg: "already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.overwrite, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.conf")
+			if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			err := Write([]config.FileConfig{{Path: path, Content: "new", Owner: currentOwner(), Overwrite: tt.overwrite}})
+			if tt.errorMsg == "" && err != nil {
+				t.Errorf("Write failed: %v", err)
+			}
+			if tt.errorMsg != "" && (err == nil || !strings.Contains(err.Error(), tt.errorMsg)) {
+				t.Errorf("Expected error containing '%s', got %v", tt.errorMsg, err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestWrite_ReplacesSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	if err := os.WriteFile(target, []byte("target"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	path := filepath.Join(dir, "link")
+	if err := os.Symlink(target, path); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	if err := Write([]config.FileConfig{{Path: path, Content: "new", Owner: currentOwner()}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if data, _ := os.ReadFile(target); string(data) != "target" {
+		t.Errorf("Expected the symlink target to be left alone, got %q", data)
+	}
+	if info, err := os.Lstat(path); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("Expected the symlink to be replaced by a regular file")
+	}
+}
+
+func TestWrite_ReportsEveryFailure(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	files := []config.FileConfig{
+		{Path: filepath.Join(blocker, "a"), Owner: currentOwner()},
+		{Path: filepath.Join(dir, "ok"), Content: "ok", Owner: currentOwner()},
+		{Path: filepath.Join(dir, "b"), Owner: "nosuchuser"},
+	}
+
+	err := Write(files)
+	if err == nil {
+		t.Fatal("Expected error but got nil")
+	}
+	for _, path := range []string{files[0].Path, files[2].Path} {
+		if !strings.Contains(err.Error(), "file "+path+": ") {
+			t.Errorf("Expected an error for %s, got %v", path, err)
+		}
+	}
+	if _, err := os.Stat(files[1].Path); err != nil {
+		t.Errorf("Expected the other files to still be written, got %v", err)
+	}
+}
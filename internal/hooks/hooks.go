@@ -0,0 +1,201 @@
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
+	"github.com/TheRealSibasishBehera/init-go/internal/secrets"
+	"github.com/TheRealSibasishBehera/init-go/internal/supervisor"
+)
+
+// maxOutput bounds how much of a hook's output is kept for the API. All of
+// it is still logged.
+const maxOutput = 64 * 1024
+
+// outputDrainTimeout bounds how long a finished hook's output is read when
+// a background child of the hook keeps the pipe open.
+const outputDrainTimeout = time.Second
+
+// Result is the outcome of a single hook run.
+type Result struct {
+	Phase     string                 `json:"phase"`
+	Index     int                    `json:"index"`
+	Command   []string               `json:"command"`
+	OnFailure string                 `json:"on_failure"`
+	StartedAt time.Time              `json:"started_at"`
+	EndedAt   time.Time              `json:"ended_at"`
+	TimedOut  bool                   `json:"timed_out"`
+	Exit      *supervisor.ExitStatus `json:"exit,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Output    string                 `json:"output"`
+}
+
+// Succeeded reports whether the hook ran to completion with exit code 0.
+func (r Result) Succeeded() bool {
+	return !r.TimedOut && r.Exit != nil && r.Exit.Succeeded()
+}
+
+// Runner runs lifecycle hooks and keeps their results.
+type Runner struct {
+	reaper   *reaper.Reaper
+	redactor *secrets.Redactor
+	mu       sync.RWMutex
+	results  []Result
+}
+
+// New creates a Runner that starts hooks through r. Hooks see the
+// application's secrets, so their output is kept only after redactor has
+// removed them.
+func New(r *reaper.Reaper, redactor *secrets.Redactor) *Runner {
+	return &Runner{reaper: r, redactor: redactor}
+}
+
+// Run runs the hooks of phase one after another with the given base
+// environment, working directory and credentials. It stops at the first
+// failing hook whose failure policy is abort and returns its error; failures
+// of other hooks are only logged.
+func (h *Runner) Run(phase string, hooks []config.HookConfig, env []string, dir string, credential *syscall.Credential) error {
+	for i, hook := range hooks {
+		log.Printf("Running %s hook %d %v", phase, i, hook.Command)
+		result := h.run(phase, i, hook, env, dir, credential)
+
+		h.mu.Lock()
+		h.results = append(h.results, result)
+		h.mu.Unlock()
+
+		if result.Succeeded() {
+			log.Printf("Hook %s %d finished in %s", phase, i, result.EndedAt.Sub(result.StartedAt))
+			continue
+		}
+
+		err := fmt.Errorf("%s hook %d %v %s", phase, i, hook.Command, failure(result))
+		if hook.GetOnFailure() == config.HookFailureIgnore {
+			log.Printf("Ignoring failed hook: %v", err)
+			continue
+		}
+		return err
+	}
+	return nil
+}
+
+// Results returns the results of every hook run so far, in order.
+func (h *Runner) Results() []Result {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	results := make([]Result, len(h.results))
+	copy(results, h.results)
+	return results
+}
+
+func (h *Runner) run(phase string, index int, hook config.HookConfig, env []string, dir string, credential *syscall.Credential) (result Result) {
+	result = Result{
+		Phase:     phase,
+		Index:     index,
+		Command:   hook.Command,
+		OnFailure: hook.GetOnFailure(),
+		StartedAt: time.Now(),
+	}
+	defer func() { result.EndedAt = time.Now() }()
+
+	cmd := exec.Command(hook.Command[0], hook.Command[1:]...)
+	cmd.Env = config.MergeEnv(env, hook.Env)
+	cmd.Dir = dir
+	// a process group lets a timed out hook be killed with its children
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Credential: credential}
+
+	// the reaper owns the exit status, so output is read through a pipe
+	// rather than collected by cmd.Wait
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer pr.Close()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	process, err := h.reaper.Start(cmd)
+	pw.Close()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	var output bytes.Buffer
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		collectOutput(pr, &output, phase, index)
+	}()
+
+	timeout := time.NewTimer(hook.GetTimeout())
+	defer timeout.Stop()
+
+wait:
+	for {
+		select {
+		case <-process.Done():
+			break wait
+		case <-timeout.C:
+			result.TimedOut = true
+			syscall.Kill(-process.Pid, syscall.SIGKILL)
+		}
+	}
+
+	select {
+	case <-outputDone:
+	case <-time.After(outputDrainTimeout):
+		pr.Close()
+		<-outputDone
+	}
+
+	exit := supervisor.NewExitStatus(process.Wait())
+	result.Exit = &exit
+	result.Output = h.redactor.Redact(output.String())
+	return result
+}
+
+// collectOutput logs every line read from r and keeps up to maxOutput bytes
+// of it in buf.
+func collectOutput(r io.Reader, buf *bytes.Buffer, phase string, index int) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			log.Printf("[%s %d] %s", phase, index, strings.TrimRight(line, "\n"))
+			if room := maxOutput - buf.Len(); room > 0 {
+				if len(line) > room {
+					line = line[:room]
+				}
+				buf.WriteString(line)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func failure(r Result) string {
+	switch {
+	case r.Error != "":
+		return "failed to start: " + r.Error
+	case r.TimedOut:
+		return "timed out"
+	case r.Exit != nil:
+		return r.Exit.Reason
+	}
+	return "failed"
+}
@@ -0,0 +1,110 @@
+package hooks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper/reapertest"
+	"github.com/TheRealSibasishBehera/init-go/internal/secrets"
+)
+
+func newTestRunner() *Runner {
+	return New(reapertest.Reaper(), secrets.NewRedactor(nil))
+}
+
+func TestRunner_Run(t *testing.T) {
+	h := newTestRunner()
+
+	hooks := []config.HookConfig{
+		{Command: []string{"sh", "-c", "echo $GREETING $NAME; echo oops >&2"}, Env: map[string]string{"NAME": "hook"}},
+	}
+	if err := h.Run(config.HookPreStart, hooks, []string{"GREETING=hello"}, "", nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	results := h.Results()
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	result := results[0]
+	if !result.Succeeded() {
+		t.Errorf("Expected hook to succeed, got %+v", result)
+	}
+	if result.Phase != config.HookPreStart || result.Index != 0 {
+		t.Errorf("Expected preStart hook 0, got %s hook %d", result.Phase, result.Index)
+	}
+	if !strings.Contains(result.Output, "hello hook") || !strings.Contains(result.Output, "oops") {
+		t.Errorf("Expected stdout and stderr in output, got %q", result.Output)
+	}
+	if result.EndedAt.Before(result.StartedAt) {
+		t.Errorf("Expected end time after start time")
+	}
+}
+
+func TestRunner_AbortStopsPhase(t *testing.T) {
+	h := newTestRunner()
+
+	hooks := []config.HookConfig{
+		{Command: []string{"sh", "-c", "exit 3"}},
+		{Command: []string{"true"}},
+	}
+	err := h.Run(config.HookPreStart, hooks, nil, "", nil)
+	if err == nil {
+		t.Fatal("Expected error from failing hook, got nil")
+	}
+	if !strings.Contains(err.Error(), "exited with code 3") {
+		t.Errorf("Expected exit code in error, got %v", err)
+	}
+	if results := h.Results(); len(results) != 1 {
+		t.Errorf("Expected the remaining hooks to be skipped, got %d results", len(results))
+	}
+}
+
+func TestRunner_IgnoreFailure(t *testing.T) {
+	h := newTestRunner()
+
+	hooks := []config.HookConfig{
+		{Command: []string{"sh", "-c", "exit 1"}, OnFailure: config.HookFailureIgnore},
+		{Command: []string{"/nonexistent/hook"}, OnFailure: config.HookFailureIgnore},
+		{Command: []string{"true"}},
+	}
+	if err := h.Run(config.HookPostStart, hooks, nil, "", nil); err != nil {
+		t.Fatalf("Expected ignored failures, got %v", err)
+	}
+
+	results := h.Results()
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if results[0].Succeeded() || results[0].Exit == nil || results[0].Exit.Code() != 1 {
+		t.Errorf("Expected first hook to fail with code 1, got %+v", results[0])
+	}
+	if results[1].Error == "" || results[1].Exit != nil {
+		t.Errorf("Expected second hook to fail to start, got %+v", results[1])
+	}
+	if !results[2].Succeeded() {
+		t.Errorf("Expected third hook to succeed, got %+v", results[2])
+	}
+}
+
+func TestRunner_Timeout(t *testing.T) {
+	h := newTestRunner()
+
+	hooks := []config.HookConfig{
+		{Command: []string{"sh", "-c", "sleep 30 & wait"}, Timeout: 1},
+	}
+
+	start := time.Now()
+	err := h.Run(config.HookPreStop, hooks, nil, "", nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected hook to be killed after its timeout, took %s", elapsed)
+	}
+	if result := h.Results()[0]; !result.TimedOut {
+		t.Errorf("Expected result to be marked as timed out, got %+v", result)
+	}
+}
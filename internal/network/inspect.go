@@ -0,0 +1,173 @@
+//go:build linux
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/system"
+	psnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+var neighborStates = []struct {
+	state int
+	name  string
+}{
+	{netlink.NUD_INCOMPLETE, "incomplete"},
+	{netlink.NUD_REACHABLE, "reachable"},
+	{netlink.NUD_STALE, "stale"},
+	{netlink.NUD_DELAY, "delay"},
+	{netlink.NUD_PROBE, "probe"},
+	{netlink.NUD_FAILED, "failed"},
+	{netlink.NUD_NOARP, "noarp"},
+	{netlink.NUD_PERMANENT, "permanent"},
+}
+
+// Inspect reads the state of the guest's network.
+func Inspect() (*State, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+
+	state := &State{
+		Links:     []LinkState{},
+		Routes:    []RouteState{},
+		Neighbors: []NeighborState{},
+	}
+	names := make(map[int]string, len(links))
+	for _, link := range links {
+		attrs := link.Attrs()
+		names[attrs.Index] = attrs.Name
+
+		addrs, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return nil, fmt.Errorf("interface %s: failed to list addresses: %w", attrs.Name, err)
+		}
+		linkState := LinkState{
+			Index:        attrs.Index,
+			Name:         attrs.Name,
+			Type:         link.Type(),
+			HardwareAddr: attrs.HardwareAddr.String(),
+			MTU:          attrs.MTU,
+			Up:           attrs.Flags&net.FlagUp != 0,
+			OperState:    attrs.OperState.String(),
+			Addresses:    make([]string, 0, len(addrs)),
+		}
+		for _, addr := range addrs {
+			linkState.Addresses = append(linkState.Addresses, addr.IPNet.String())
+		}
+		state.Links = append(state.Links, linkState)
+	}
+
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: unix.RT_TABLE_UNSPEC}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routes: %w", err)
+	}
+	for _, route := range routes {
+		if route.Table == unix.RT_TABLE_LOCAL {
+			continue
+		}
+		state.Routes = append(state.Routes, routeState(route, names))
+	}
+
+	neighbors, err := netlink.NeighList(0, netlink.FAMILY_ALL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list neighbors: %w", err)
+	}
+	for _, neighbor := range neighbors {
+		state.Neighbors = append(state.Neighbors, NeighborState{
+			IP:           neighbor.IP.String(),
+			HardwareAddr: neighbor.HardwareAddr.String(),
+			Device:       names[neighbor.LinkIndex],
+			State:        neighborState(neighbor.State),
+		})
+	}
+
+	state.Sockets, err = listeningSockets()
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func routeState(route netlink.Route, names map[int]string) RouteState {
+	r := RouteState{
+		Destination: "default",
+		Device:      names[route.LinkIndex],
+		Metric:      route.Priority,
+		Table:       route.Table,
+		Type:        strconv.Itoa(route.Type),
+	}
+	if route.Dst != nil {
+		if ones, _ := route.Dst.Mask.Size(); ones != 0 {
+			r.Destination = route.Dst.String()
+		}
+	}
+	if route.Gw != nil {
+		r.Gateway = route.Gw.String()
+	}
+	if route.Src != nil {
+		r.Source = route.Src.String()
+	}
+	for name, t := range routeTypes {
+		if t == route.Type {
+			r.Type = name
+		}
+	}
+	return r
+}
+
+func neighborState(state int) string {
+	for _, s := range neighborStates {
+		if state&s.state != 0 {
+			return s.name
+		}
+	}
+	return "none"
+}
+
+// listeningSockets returns the TCP sockets in the LISTEN state and the
+// unconnected UDP sockets, with the processes that own them.
+func listeningSockets() ([]SocketState, error) {
+	connections, err := psnet.Connections("inet")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sockets: %w", err)
+	}
+	processes, err := system.ListProcesses()
+	if err != nil {
+		return nil, err
+	}
+	comms := make(map[int]string, len(processes))
+	for _, process := range processes {
+		comms[process.Pid] = process.Comm
+	}
+
+	sockets := []SocketState{}
+	for _, c := range connections {
+		var protocol string
+		switch {
+		case c.Type == syscall.SOCK_STREAM && c.Status == "LISTEN":
+			protocol = "tcp"
+		case c.Type == syscall.SOCK_DGRAM && c.Raddr.Port == 0:
+			protocol = "udp"
+		default:
+			continue
+		}
+		if c.Family == syscall.AF_INET6 {
+			protocol += "6"
+		}
+		sockets = append(sockets, SocketState{
+			Protocol:     protocol,
+			LocalAddress: net.JoinHostPort(c.Laddr.IP, strconv.Itoa(int(c.Laddr.Port))),
+			Pid:          int(c.Pid),
+			Comm:         comms[int(c.Pid)],
+		})
+	}
+	return sockets, nil
+}
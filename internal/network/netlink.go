@@ -0,0 +1,269 @@
+//go:build linux
+
+package network
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// LoopbackInterface is brought up whether or not it is configured.
+const LoopbackInterface = "lo"
+
+// Configure brings up the loopback interface and applies cfg's ipConfigs:
+// the addresses are assigned, the MTU is set, the links are brought up and
+// default routes are installed through the gateways. The static routes
+// and rules follow once the interfaces are up. Everything is attempted;
+// the returned error lists each failure.
+func Configure(cfg *config.RunConfig) error {
+	var errs []error
+	if err := setUp(LoopbackInterface); err != nil {
+		errs = append(errs, err)
+	}
+	for _, link := range plan(cfg.IPConfigs) {
+		if err := configure(link); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, r := range cfg.Routes {
+		if err := AddRoute(r); err != nil {
+			errs = append(errs, fmt.Errorf("route %s: %w", r, err))
+			continue
+		}
+		log.Printf("Added route %s", r)
+	}
+	for _, r := range cfg.Rules {
+		if err := addRule(r); err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", r, err))
+			continue
+		}
+		log.Printf("Added rule %s", r)
+	}
+	return errors.Join(errs...)
+}
+
+// linkByName looks up the interface name, failing with
+// ErrUnknownInterface if there is none.
+func linkByName(name string) (netlink.Link, error) {
+	link, err := netlink.LinkByName(name)
+	var notFound netlink.LinkNotFoundError
+	if errors.As(err, &notFound) {
+		return nil, fmt.Errorf("interface %s: %w", name, ErrUnknownInterface)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("interface %s: %w", name, err)
+	}
+	return link, nil
+}
+
+func setUp(name string) error {
+	link, err := linkByName(name)
+	if err != nil {
+		return err
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return fmt.Errorf("interface %s: failed to bring up: %w", name, err)
+	}
+	return nil
+}
+
+func configure(l *Link) error {
+	link, err := linkByName(l.Name)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, addr := range l.Addresses {
+		if err := netlink.AddrReplace(link, &netlink.Addr{IPNet: addr}); err != nil {
+			errs = append(errs, fmt.Errorf("interface %s: failed to add address %s: %w", l.Name, addr, err))
+			continue
+		}
+		log.Printf("Added address %s to %s", addr, l.Name)
+	}
+
+	if l.MTU != 0 {
+		if err := netlink.LinkSetMTU(link, l.MTU); err != nil {
+			errs = append(errs, fmt.Errorf("interface %s: failed to set MTU %d: %w", l.Name, l.MTU, err))
+		}
+	}
+
+	if err := netlink.LinkSetUp(link); err != nil {
+		// without the link up the kernel has no route to the gateways
+		return errors.Join(append(errs, fmt.Errorf("interface %s: failed to bring up: %w", l.Name, err))...)
+	}
+
+	for _, route := range l.Routes {
+		err := netlink.RouteReplace(&netlink.Route{
+			LinkIndex: link.Attrs().Index,
+			Gw:        route.Gateway,
+			Priority:  route.Metric,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("interface %s: failed to add default route via %s: %w", l.Name, route.Gateway, err))
+			continue
+		}
+		log.Printf("Added default route via %s on %s", route.Gateway, l.Name)
+	}
+	return errors.Join(errs...)
+}
+
+var routeTypes = map[string]int{
+	config.RouteTypeUnicast:     unix.RTN_UNICAST,
+	config.RouteTypeBlackhole:   unix.RTN_BLACKHOLE,
+	config.RouteTypeUnreachable: unix.RTN_UNREACHABLE,
+	config.RouteTypeProhibit:    unix.RTN_PROHIBIT,
+}
+
+// AddRoute adds r, replacing a route to the same destination with the
+// same metric in the same table.
+func AddRoute(r config.RouteConfig) error {
+	route, err := routeOnDevice(r)
+	if err != nil {
+		return err
+	}
+	return netlink.RouteReplace(route)
+}
+
+// DeleteRoute removes r.
+func DeleteRoute(r config.RouteConfig) error {
+	route, err := routeOnDevice(r)
+	if err != nil {
+		return err
+	}
+	return netlink.RouteDel(route)
+}
+
+func routeOnDevice(r config.RouteConfig) (*netlink.Route, error) {
+	route, err := toRoute(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Device != "" {
+		link, err := linkByName(r.Device)
+		if err != nil {
+			return nil, err
+		}
+		route.LinkIndex = link.Attrs().Index
+	}
+	return route, nil
+}
+
+// AddAddress assigns addr to the interface name.
+func AddAddress(name string, addr *net.IPNet) error {
+	link, err := linkByName(name)
+	if err != nil {
+		return err
+	}
+	if err := netlink.AddrAdd(link, &netlink.Addr{IPNet: addr}); err != nil {
+		return fmt.Errorf("interface %s: failed to add address %s: %w", name, addr, err)
+	}
+	return nil
+}
+
+// DeleteAddress removes addr from the interface name.
+func DeleteAddress(name string, addr *net.IPNet) error {
+	link, err := linkByName(name)
+	if err != nil {
+		return err
+	}
+	if err := netlink.AddrDel(link, &netlink.Addr{IPNet: addr}); err != nil {
+		return fmt.Errorf("interface %s: failed to delete address %s: %w", name, addr, err)
+	}
+	return nil
+}
+
+// toRoute converts r to a netlink route, leaving out the device.
+func toRoute(r config.RouteConfig) (*netlink.Route, error) {
+	dst, err := r.GetDestination()
+	if err != nil {
+		return nil, err
+	}
+	route := &netlink.Route{
+		Dst:      dst,
+		Gw:       net.ParseIP(r.Gateway),
+		Priority: r.Metric,
+		Table:    r.Table,
+		Type:     routeTypes[r.GetType()],
+		Family:   family(dst.IP),
+	}
+	if route.Gw == nil && r.GetType() == config.RouteTypeUnicast {
+		// without a gateway the destination is on the device's link
+		route.Scope = netlink.SCOPE_LINK
+	}
+	return route, nil
+}
+
+func addRule(r config.RuleConfig) error {
+	rules, err := toRules(r)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if err := netlink.RuleAdd(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toRules converts r to netlink rules, one per address family it applies
+// to.
+func toRules(r config.RuleConfig) ([]*netlink.Rule, error) {
+	src, err := parseSelector(r.From)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := parseSelector(r.To)
+	if err != nil {
+		return nil, err
+	}
+
+	families := []int{netlink.FAMILY_V4, netlink.FAMILY_V6}
+	switch {
+	case src != nil:
+		families = []int{family(src.IP)}
+	case dst != nil:
+		families = []int{family(dst.IP)}
+	}
+
+	rules := make([]*netlink.Rule, 0, len(families))
+	for _, f := range families {
+		rule := netlink.NewRule()
+		rule.Family = f
+		rule.Src = src
+		rule.Dst = dst
+		rule.IifName = r.Iif
+		rule.OifName = r.Oif
+		rule.Table = r.Table
+		if r.Priority != 0 {
+			rule.Priority = r.Priority
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseSelector parses the network of a rule selector, which may be
+// empty.
+func parseSelector(s string) (*net.IPNet, error) {
+	if s == "" {
+		return nil, nil
+	}
+	_, network, err := net.ParseCIDR(s)
+	return network, err
+}
+
+func family(ip net.IP) int {
+	if ip.To4() != nil {
+		return netlink.FAMILY_V4
+	}
+	return netlink.FAMILY_V6
+}
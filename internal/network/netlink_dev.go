@@ -0,0 +1,48 @@
+//go:build !linux
+
+package network
+
+import (
+	"log"
+	"net"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+)
+
+// Configure is a development stub for non-Linux platforms
+func Configure(cfg *config.RunConfig) error {
+	for _, link := range plan(cfg.IPConfigs) {
+		log.Printf("[DEV] Would configure %s with %v", link.Name, link.Addresses)
+	}
+	for _, r := range cfg.Routes {
+		log.Printf("[DEV] Would add route %s", r)
+	}
+	for _, r := range cfg.Rules {
+		log.Printf("[DEV] Would add rule %s", r)
+	}
+	return nil
+}
+
+// AddRoute is a development stub for non-Linux platforms
+func AddRoute(r config.RouteConfig) error {
+	log.Printf("[DEV] Would add route %s", r)
+	return nil
+}
+
+// DeleteRoute is a development stub for non-Linux platforms
+func DeleteRoute(r config.RouteConfig) error {
+	log.Printf("[DEV] Would delete route %s", r)
+	return nil
+}
+
+// AddAddress is a development stub for non-Linux platforms
+func AddAddress(name string, addr *net.IPNet) error {
+	log.Printf("[DEV] Would add address %s to %s", addr, name)
+	return nil
+}
+
+// DeleteAddress is a development stub for non-Linux platforms
+func DeleteAddress(name string, addr *net.IPNet) error {
+	log.Printf("[DEV] Would delete address %s from %s", addr, name)
+	return nil
+}
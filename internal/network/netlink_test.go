@@ -0,0 +1,80 @@
+//go:build linux
+
+package network
+
+import (
+	"testing"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func TestToRoute(t *testing.T) {
+	tests := []struct {
+		name   string
+		route  config.RouteConfig
+		check  func(*netlink.Route) bool
+		expect string
+	}{
+		{
+			name:  "Via gateway",
+			route: config.RouteConfig{Destination: "10.0.0.0/8", Gateway: "172.19.0.1", Metric: 10, Table: 100},
+			check: func(r *netlink.Route) bool {
+				return r.Gw.String() == "172.19.0.1" && r.Priority == 10 && r.Table == 100 && r.Scope == netlink.SCOPE_UNIVERSE
+			},
+			expect: "a universe route via 172.19.0.1 with metric 10 in table 100",
+		},
+		{
+			name:   "On link",
+			route:  config.RouteConfig{Destination: "192.168.0.0/24", Device: "eth1"},
+			check:  func(r *netlink.Route) bool { return r.Gw == nil && r.Scope == netlink.SCOPE_LINK },
+			expect: "a link scope route",
+		},
+		{
+			name:   "Default IPv6",
+			route:  config.RouteConfig{Destination: "default", Gateway: "fdaa::1"},
+			check:  func(r *netlink.Route) bool { return r.Dst.String() == "::/0" && r.Family == netlink.FAMILY_V6 },
+			expect: "an IPv6 default route",
+		},
+		{
+			name:   "Blackhole",
+			route:  config.RouteConfig{Destination: "169.254.169.254/32", Type: config.RouteTypeBlackhole},
+			check:  func(r *netlink.Route) bool { return r.Type == unix.RTN_BLACKHOLE && r.Scope == netlink.SCOPE_UNIVERSE },
+			expect: "a blackhole route",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := toRoute(tt.route)
+			if err != nil {
+				t.Fatalf("toRoute failed: %v", err)
+			}
+			if !tt.check(route) {
+				t.Errorf("Expected %s, got %+v", tt.expect, route)
+			}
+		})
+	}
+}
+
+func TestToRules(t *testing.T) {
+	rules, err := toRules(config.RuleConfig{Oif: "eth1", Table: 100})
+	if err != nil {
+		t.Fatalf("toRules failed: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Family != netlink.FAMILY_V4 || rules[1].Family != netlink.FAMILY_V6 {
+		t.Errorf("Expected a rule per address family, got %+v", rules)
+	}
+	if rules[0].Priority != -1 || rules[0].OifName != "eth1" || rules[0].Table != 100 {
+		t.Errorf("Expected oif eth1, table 100 and no priority, got %+v", rules[0])
+	}
+
+	rules, err = toRules(config.RuleConfig{To: "fd00::/8", Priority: 100, Table: 100})
+	if err != nil {
+		t.Fatalf("toRules failed: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Family != netlink.FAMILY_V6 || rules[0].Priority != 100 {
+		t.Errorf("Expected one IPv6 rule with priority 100, got %+v", rules)
+	}
+}
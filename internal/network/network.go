@@ -0,0 +1,59 @@
+package network
+
+import (
+	"net"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+)
+
+// defaultRouteMetric is the metric of the first default route of each
+// address family. Further gateways of the same family get higher metrics,
+// so the first one configured is preferred and the others are fallbacks.
+const defaultRouteMetric = 100
+
+// Link is the configuration of one network interface, gathered from the
+// ipConfigs assigned to it.
+type Link struct {
+	Name      string
+	Addresses []*net.IPNet
+	MTU       int
+	Routes    []DefaultRoute
+}
+
+// DefaultRoute is a default route through a gateway.
+type DefaultRoute struct {
+	Gateway net.IP
+	Metric  int
+}
+
+// plan groups ipConfigs by interface, in the order the interfaces are
+// first mentioned.
+func plan(ipConfigs []config.IPConfig) []*Link {
+	var links []*Link
+	byName := make(map[string]*Link)
+	metrics := make(map[bool]int)
+
+	for _, ipConfig := range ipConfigs {
+		name := ipConfig.GetInterface()
+		link, ok := byName[name]
+		if !ok {
+			link = &Link{Name: name}
+			byName[name] = link
+			links = append(links, link)
+		}
+
+		link.Addresses = append(link.Addresses, ipConfig.IP)
+		if link.MTU == 0 {
+			link.MTU = ipConfig.MTU
+		}
+		if ipConfig.Gateway != nil {
+			ipv6 := ipConfig.IsIPv6()
+			link.Routes = append(link.Routes, DefaultRoute{
+				Gateway: ipConfig.Gateway,
+				Metric:  defaultRouteMetric + metrics[ipv6],
+			})
+			metrics[ipv6]++
+		}
+	}
+	return links
+}
@@ -0,0 +1,82 @@
+package network
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+)
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	ipNet.IP = ip
+	return ipNet
+}
+
+func TestPlan(t *testing.T) {
+	ipConfigs := []config.IPConfig{
+		{IP: mustParseCIDR("172.19.0.2/24"), Gateway: net.ParseIP("172.19.0.1"), MTU: 1420},
+		{IP: mustParseCIDR("10.0.0.2/16"), Gateway: net.ParseIP("10.0.0.1"), Interface: "eth1"},
+		{IP: mustParseCIDR("fdaa::2/64"), Gateway: net.ParseIP("fdaa::1"), Interface: "eth0"},
+	}
+
+	expected := []*Link{
+		{
+			Name:      "eth0",
+			Addresses: []*net.IPNet{ipConfigs[0].IP, ipConfigs[2].IP},
+			MTU:       1420,
+			Routes: []DefaultRoute{
+				{Gateway: net.ParseIP("172.19.0.1"), Metric: 100},
+				{Gateway: net.ParseIP("fdaa::1"), Metric: 100},
+			},
+		},
+		{
+			Name:      "eth1",
+			Addresses: []*net.IPNet{ipConfigs[1].IP},
+			Routes:    []DefaultRoute{{Gateway: net.ParseIP("10.0.0.1"), Metric: 101}},
+		},
+	}
+
+	links := plan(ipConfigs)
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, links)
+	}
+}
+
+func TestPlan_Empty(t *testing.T) {
+	if links := plan(nil); len(links) != 0 {
+		t.Errorf("Expected no links, got %+v", links)
+	}
+}
+
+func TestAddressRequest_Parse(t *testing.T) {
+	tests := []struct {
+		req      AddressRequest
+		expected string
+		errorMsg string
+	}{
+		{req: AddressRequest{Interface: "eth0", Address: "10.0.0.5/24"}, expected: "10.0.0.5/24"},
+		{req: AddressRequest{Interface: "eth0", Address: "fdaa::5/64"}, expected: "fdaa::5/64"},
+		{req: AddressRequest{Address: "10.0.0.5/24"}, errorMsg: "interface is required"},
+		{req: AddressRequest{Interface: "eth0", Address: "10.0.0.5"}, errorMsg: "invalid address 10.0.0.5, expected a CIDR"},
+	}
+
+	for _, tt := range tests {
+		addr, err := tt.req.Parse()
+		if tt.errorMsg != "" {
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("Expected error '%s', got %v", tt.errorMsg, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%+v) failed: %v", tt.req, err)
+		} else if addr.String() != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, addr)
+		}
+	}
+}
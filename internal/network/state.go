@@ -0,0 +1,79 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ErrUnknownInterface is returned for an interface that does not exist.
+var ErrUnknownInterface = errors.New("no such interface")
+
+// State is a snapshot of the guest's network as reported by the API:
+// every link with its addresses, the routes outside the local table, the
+// neighbor cache and the sockets that accept connections or datagrams.
+type State struct {
+	Links     []LinkState     `json:"links"`
+	Routes    []RouteState    `json:"routes"`
+	Neighbors []NeighborState `json:"neighbors"`
+	Sockets   []SocketState   `json:"sockets"`
+}
+
+type LinkState struct {
+	Index        int      `json:"index"`
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	HardwareAddr string   `json:"hardware_addr,omitempty"`
+	MTU          int      `json:"mtu"`
+	Up           bool     `json:"up"`
+	OperState    string   `json:"oper_state"`
+	Addresses    []string `json:"addresses"`
+}
+
+type RouteState struct {
+	Destination string `json:"destination"`
+	Gateway     string `json:"gateway,omitempty"`
+	Device      string `json:"device,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Metric      int    `json:"metric"`
+	Table       int    `json:"table"`
+	Type        string `json:"type"`
+}
+
+type NeighborState struct {
+	IP           string `json:"ip"`
+	HardwareAddr string `json:"hardware_addr,omitempty"`
+	Device       string `json:"device"`
+	State        string `json:"state"`
+}
+
+// SocketState is a listening TCP socket or a bound UDP socket and the
+// process that owns it, if it could be found.
+type SocketState struct {
+	Protocol     string `json:"protocol"`
+	LocalAddress string `json:"local_address"`
+	Pid          int    `json:"pid,omitempty"`
+	Comm         string `json:"comm,omitempty"`
+}
+
+// AddressRequest adds or removes an address over the API.
+type AddressRequest struct {
+	Interface string `json:"interface"`
+	Address   string `json:"address"`
+}
+
+// Parse returns the address in CIDR notation, keeping the host address.
+func (r AddressRequest) Parse() (*net.IPNet, error) {
+	if r.Interface == "" {
+		return nil, fmt.Errorf("interface is required")
+	}
+	ip, ipNet, err := net.ParseCIDR(r.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s, expected a CIDR", r.Address)
+	}
+	ipNet.IP = ip
+	if ip4 := ip.To4(); ip4 != nil {
+		ipNet.IP = ip4
+	}
+	return ipNet, nil
+}
@@ -0,0 +1,168 @@
+//go:build linux
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// pollInterval is how often the network is checked while waiting.
+	pollInterval = 100 * time.Millisecond
+
+	// dialTimeout bounds each attempt to connect to the wait address, so
+	// a dropped SYN is retried rather than waited out.
+	dialTimeout = time.Second
+
+	// discardPort is where datagrams that make the kernel resolve a
+	// gateway are sent. Nothing is expected to listen on it.
+	discardPort = 9
+)
+
+// Wait blocks until the network configured by cfg is ready, as described
+// by config.NetworkWaitConfig, or until the wait times out.
+func Wait(cfg *config.RunConfig) error {
+	wait := cfg.WaitForNetwork
+	ctx, cancel := context.WithTimeout(context.Background(), wait.GetTimeout())
+	defer cancel()
+
+	start := time.Now()
+	if err := waitFor(ctx, cfg); err != nil {
+		return fmt.Errorf("network not ready after %s: %w", time.Since(start).Round(time.Millisecond), err)
+	}
+	log.Printf("Network ready after %s", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func waitFor(ctx context.Context, cfg *config.RunConfig) error {
+	for _, l := range plan(cfg.IPConfigs) {
+		link, err := linkByName(l.Name)
+		if err != nil {
+			return err
+		}
+		if err := waitForDAD(ctx, link, l.Addresses); err != nil {
+			return err
+		}
+		for _, route := range l.Routes {
+			if err := waitForNeighbor(ctx, link, route.Gateway); err != nil {
+				return err
+			}
+		}
+	}
+
+	if address := cfg.WaitForNetwork.Address; address != "" {
+		return waitForAddress(ctx, address)
+	}
+	return nil
+}
+
+// waitForDAD waits until the IPv6 addresses among addrs are no longer
+// tentative, i.e. duplicate address detection has passed. Until then the
+// kernel does not use them.
+func waitForDAD(ctx context.Context, link netlink.Link, addrs []*net.IPNet) error {
+	name := link.Attrs().Name
+	return poll(ctx, "duplicate address detection on "+name, func() (bool, error) {
+		current, err := netlink.AddrList(link, netlink.FAMILY_V6)
+		if err != nil {
+			return false, fmt.Errorf("interface %s: %w", name, err)
+		}
+		for _, addr := range current {
+			if !contains(addrs, addr.IP) {
+				continue
+			}
+			if addr.Flags&unix.IFA_F_DADFAILED != 0 {
+				return false, fmt.Errorf("interface %s: address %s failed duplicate address detection", name, addr.IPNet)
+			}
+			if addr.Flags&unix.IFA_F_TENTATIVE != 0 {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// waitForNeighbor waits until gateway answers ARP or neighbor discovery
+// on link, prompting the kernel to ask for it as long as it has not.
+func waitForNeighbor(ctx context.Context, link netlink.Link, gateway net.IP) error {
+	attrs := link.Attrs()
+	if attrs.RawFlags&unix.IFF_NOARP != 0 {
+		return nil
+	}
+	what := fmt.Sprintf("gateway %s on %s to answer", gateway, attrs.Name)
+	return poll(ctx, what, func() (bool, error) {
+		neighbors, err := netlink.NeighList(attrs.Index, family(gateway))
+		if err != nil {
+			return false, fmt.Errorf("interface %s: %w", attrs.Name, err)
+		}
+		for _, neighbor := range neighbors {
+			if neighbor.IP.Equal(gateway) && neighbor.HardwareAddr != nil &&
+				neighbor.State&(netlink.NUD_INCOMPLETE|netlink.NUD_FAILED) == 0 {
+				return true, nil
+			}
+		}
+		solicit(gateway, attrs.Name)
+		return false, nil
+	})
+}
+
+// solicit makes the kernel resolve the link-layer address of ip by
+// sending it a datagram.
+func solicit(ip net.IP, iface string) {
+	addr := &net.UDPAddr{IP: ip, Port: discardPort}
+	if ip.IsLinkLocalUnicast() {
+		addr.Zone = iface
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.Write([]byte{0})
+}
+
+// waitForAddress waits until a TCP connection to address succeeds.
+func waitForAddress(ctx context.Context, address string) error {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	return poll(ctx, "a connection to "+address, func() (bool, error) {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return false, nil
+		}
+		conn.Close()
+		return true, nil
+	})
+}
+
+// poll calls ready until it reports true or fails, or ctx is done.
+func poll(ctx context.Context, what string, ready func() (bool, error)) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		ok, err := ready()
+		if err != nil || ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("still waiting for %s", what)
+		case <-ticker.C:
+		}
+	}
+}
+
+func contains(addrs []*net.IPNet, ip net.IP) bool {
+	for _, addr := range addrs {
+		if addr.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package network
+
+import (
+	"log"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+)
+
+// Wait is a development stub for non-Linux platforms
+func Wait(cfg *config.RunConfig) error {
+	log.Printf("[DEV] Would wait up to %s for the network", cfg.WaitForNetwork.GetTimeout())
+	return nil
+}
@@ -0,0 +1,78 @@
+//go:build linux
+
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	calls := 0
+	err := poll(context.Background(), "test", func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected poll to succeed on the third call, got %v after %d calls", err, calls)
+	}
+
+	failed := errors.New("failed")
+	if err := poll(context.Background(), "test", func() (bool, error) { return false, failed }); err != failed {
+		t.Errorf("Expected the check's error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*pollInterval)
+	defer cancel()
+	err = poll(ctx, "the test", func() (bool, error) { return false, nil })
+	if err == nil || err.Error() != "still waiting for the test" {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+}
+
+func TestWaitForAddress(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	// start listening only after the wait has begun
+	go func() {
+		time.Sleep(3 * pollInterval)
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return
+		}
+		defer listener.Close()
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := waitForAddress(ctx, address); err != nil {
+		t.Errorf("Expected the address to become reachable, got %v", err)
+	}
+}
+
+func TestWaitForAddress_Timeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*pollInterval)
+	defer cancel()
+	err = waitForAddress(ctx, address)
+	if err == nil || err.Error() != "still waiting for a connection to "+address {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package reaper
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Reaper is the only caller of wait4 in init. It reaps every exited child,
+// delivers the exit status of processes started through it to their waiters
+// and discards the status of orphans reparented to init.
+//
+// Processes started through the Reaper must never be waited on with
+// exec.Cmd.Wait, and their standard streams must be files or pipes obtained
+// from StdinPipe/StdoutPipe/StderrPipe, since os/exec only finishes copying
+// to other writers inside Wait.
+type Reaper struct {
+	mu        sync.Mutex
+	processes map[int]*Process
+	childless bool
+	notify    chan struct{}
+	// passed is closed and replaced after every reap pass
+	passed chan struct{}
+}
+
+// Process is a child started through the Reaper.
+type Process struct {
+	Pid    int
+	cmd    *exec.Cmd
+	done   chan struct{}
+	status syscall.WaitStatus
+}
+
+func New() *Reaper {
+	return &Reaper{
+		processes: make(map[int]*Process),
+		notify:    make(chan struct{}, 1),
+		passed:    make(chan struct{}),
+	}
+}
+
+// Start starts cmd and registers its pid. The table lock is held across the
+// fork so a reap that races with Start still finds the waiter.
+func (r *Reaper) Start(cmd *exec.Cmd) (*Process, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &Process{
+		Pid:  cmd.Process.Pid,
+		cmd:  cmd,
+		done: make(chan struct{}),
+	}
+	r.processes[p.Pid] = p
+	r.childless = false
+	return p, nil
+}
+
+// kick asks the reaper goroutine for a reap pass. It never blocks.
+func (r *Reaper) kick() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run is the reaper goroutine. It subscribes to SIGCHLD itself and reaps
+// children every time one arrives, so processes are collected no matter
+// what the rest of init is busy with.
+func (r *Reaper) Run() {
+	sigchld := make(chan os.Signal, 1)
+	signal.Notify(sigchld, syscall.SIGCHLD)
+
+	// children may have exited before the subscription
+	r.reap()
+	for {
+		select {
+		case <-sigchld:
+		case <-r.notify:
+		}
+		r.reap()
+	}
+}
+
+func (r *Reaper) reap() {
+	childless := false
+	defer func() {
+		r.mu.Lock()
+		r.childless = childless
+		close(r.passed)
+		r.passed = make(chan struct{})
+		r.mu.Unlock()
+	}()
+
+	for {
+		var status syscall.WaitStatus
+		pid, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err == syscall.ECHILD {
+			childless = true
+			return
+		}
+		if err != nil || pid <= 0 {
+			return
+		}
+
+		r.mu.Lock()
+		p, ok := r.processes[pid]
+		delete(r.processes, pid)
+		r.mu.Unlock()
+
+		if !ok {
+			log.Printf("Reaped orphan process %d with status %d", pid, status)
+			continue
+		}
+
+		p.status = status
+		// release the pidfd held by os.Process, Wait will never do it
+		p.cmd.Process.Release()
+		close(p.done)
+	}
+}
+
+// WaitIdle waits until init has no children left or timeout expires. It
+// reports whether every child was reaped.
+func (r *Reaper) WaitIdle(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	// orphans may have been reparented since the last pass, so only a
+	// fresh pass can tell that no children are left; later passes follow
+	// SIGCHLD
+	r.mu.Lock()
+	passed := r.passed
+	r.mu.Unlock()
+	r.kick()
+
+	for {
+		select {
+		case <-passed:
+		case <-timer.C:
+			return false
+		}
+
+		r.mu.Lock()
+		childless := r.childless
+		passed = r.passed
+		r.mu.Unlock()
+		if childless {
+			return true
+		}
+	}
+}
+
+// Done is closed once the process has been reaped.
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait blocks until the process has been reaped and returns its status.
+func (p *Process) Wait() syscall.WaitStatus {
+	<-p.done
+	return p.status
+}
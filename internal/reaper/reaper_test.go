@@ -0,0 +1,86 @@
+package reaper_test
+
+import (
+	"os/exec"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper/reapertest"
+)
+
+func TestReaper_DeliversExitStatus(t *testing.T) {
+	r := reapertest.Reaper()
+
+	p, err := r.Start(exec.Command("sh", "-c", "exit 5"))
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	select {
+	case <-p.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for process to be reaped")
+	}
+
+	status := p.Wait()
+	if !status.Exited() || status.ExitStatus() != 5 {
+		t.Errorf("Expected exit code 5, got status %d", status)
+	}
+}
+
+func TestReaper_StartFailure(t *testing.T) {
+	r := reapertest.Reaper()
+
+	if _, err := r.Start(exec.Command("/nonexistent/binary")); err == nil {
+		t.Error("Expected error for missing binary, got nil")
+	}
+}
+
+func TestReaper_Concurrent(t *testing.T) {
+	r := reapertest.Reaper()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(code int) {
+			defer wg.Done()
+			p, err := r.Start(exec.Command("sh", "-c", "sleep 0.1; exit $0", strconv.Itoa(code)))
+			if err != nil {
+				t.Errorf("Start failed: %v", err)
+				return
+			}
+			if status := p.Wait(); status.ExitStatus() != code {
+				t.Errorf("Expected exit code %d, got %d", code, status.ExitStatus())
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestReaper_WaitIdle(t *testing.T) {
+	r := reapertest.Reaper()
+
+	if _, err := r.Start(exec.Command("sleep", "0.1")); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if !r.WaitIdle(5 * time.Second) {
+		t.Error("Expected every child to be reaped")
+	}
+}
+
+func TestReaper_WaitIdleTimeout(t *testing.T) {
+	r := reapertest.Reaper()
+
+	cmd := exec.Command("sleep", "5")
+	if _, err := r.Start(cmd); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	if r.WaitIdle(200 * time.Millisecond) {
+		t.Error("Expected WaitIdle to time out while a child is running")
+	}
+}
@@ -0,0 +1,24 @@
+// Package reapertest provides the reaper tests start their processes with.
+package reapertest
+
+import (
+	"sync"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
+)
+
+// shared is used by every test in a test binary, as in init there is a
+// single reaper: a second one would reap the first one's children.
+var (
+	shared     *reaper.Reaper
+	sharedOnce sync.Once
+)
+
+// Reaper returns the test binary's reaper, starting it on first use.
+func Reaper() *reaper.Reaper {
+	sharedOnce.Do(func() {
+		shared = reaper.New()
+		go shared.Run()
+	})
+	return shared
+}
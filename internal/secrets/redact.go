@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+)
+
+// Redactor replaces secret values in text with config.RedactedValue.
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// NewRedactor creates a Redactor for values. Empty values are ignored.
+func NewRedactor(values []string) *Redactor {
+	sorted := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			sorted = append(sorted, value)
+		}
+	}
+	// a value that contains another one must win, or part of it would
+	// be left in the clear
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	pairs := make([]string, 0, 2*len(sorted))
+	for _, value := range sorted {
+		pairs = append(pairs, value, config.RedactedValue)
+	}
+	return &Redactor{replacer: strings.NewReplacer(pairs...)}
+}
+
+// Redact returns s with every secret value replaced.
+func (r *Redactor) Redact(s string) string {
+	return r.replacer.Replace(s)
+}
+
+// Writer returns a writer that redacts everything written to it before
+// passing it on to w. It is meant for the log output, which the log
+// package writes one complete line at a time.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return &redactingWriter{redactor: r, w: w}
+}
+
+type redactingWriter struct {
+	redactor *Redactor
+	w        io.Writer
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, w.redactor.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
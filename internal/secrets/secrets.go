@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/user"
+)
+
+// Install writes the secrets that are exposed as files to dir, which is
+// expected to be a fresh tmpfs so their values never reach a disk. Files
+// are owned by the secret's owner, or by owner if it has none.
+func Install(dir string, secrets []config.SecretConfig, owner *user.User) error {
+	for _, secret := range secrets {
+		if secret.File == "" {
+			continue
+		}
+		if err := install(dir, secret, owner); err != nil {
+			return fmt.Errorf("secret %s: %w", secret.Name, err)
+		}
+	}
+	return nil
+}
+
+func install(dir string, secret config.SecretConfig, owner *user.User) error {
+	if secret.Owner != "" {
+		var err error
+		if owner, err = user.Lookup(secret.Owner); err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(dir, secret.File)
+	// the mode is applied once the file belongs to its owner, so nobody
+	// else can open it in between
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL|syscall.O_NOFOLLOW, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(secret.Value); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Chown(int(owner.Uid), int(owner.Gid)); err != nil {
+		return fmt.Errorf("failed to change owner of %s to %d:%d: %w", path, owner.Uid, owner.Gid, err)
+	}
+	if err := f.Chmod(secret.GetMode()); err != nil {
+		return fmt.Errorf("failed to change mode of %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// HasFiles reports whether any of secrets is exposed as a file.
+func HasFiles(secrets []config.SecretConfig) bool {
+	for _, secret := range secrets {
+		if secret.File != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package secrets
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/user"
+)
+
+func currentUser() *user.User {
+	return &user.User{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid())}
+}
+
+func TestInstall(t *testing.T) {
+	dir := t.TempDir()
+	secrets := []config.SecretConfig{
+		{Name: "db", Value: "hunter2", Env: "DB_PASSWORD"},
+		{Name: "tls", Value: "-----BEGIN KEY-----", File: "tls.key"},
+		{Name: "token", Value: "abc", File: "token", Mode: "0440"},
+	}
+
+	if err := Install(dir, secrets, currentUser()); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected only file secrets to be written, got %d files", len(entries))
+	}
+
+	tests := []struct {
+		file  string
+		value string
+		mode  os.FileMode
+	}{
+		{file: "tls.key", value: "-----BEGIN KEY-----", mode: 0400},
+		{file: "token", value: "abc", mode: 0440},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", tt.file, err)
+		}
+		if info.Mode().Perm() != tt.mode {
+			t.Errorf("Expected %s to have mode %o, got %o", tt.file, tt.mode, info.Mode().Perm())
+		}
+		if stat := info.Sys().(*syscall.Stat_t); stat.Uid != uint32(os.Getuid()) {
+			t.Errorf("Expected %s to be owned by uid %d, got %d", tt.file, os.Getuid(), stat.Uid)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil && os.Getuid() == 0 {
+			t.Fatalf("Failed to read %s: %v", tt.file, err)
+		}
+		if err == nil && string(data) != tt.value {
+			t.Errorf("Expected %s to contain %q, got %q", tt.file, tt.value, data)
+		}
+	}
+}
+
+func TestInstall_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Symlink("/etc/passwd", filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		secret   config.SecretConfig
+		errorMsg string
+	}{
+		{
+			name:     "Unknown owner",
+			secret:   config.SecretConfig{Name: "db", File: "db", Owner: "nosuchuser"},
+			errorMsg: "secret db: unable to find user nosuchuser",
+		},
+		{
+			name:     "Existing file is not replaced",
+			secret:   config.SecretConfig{Name: "link", File: "link"},
+			errorMsg: "secret link: failed to create " + filepath.Join(dir, "link"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Install(dir, []config.SecretConfig{tt.secret}, currentUser())
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if !strings.HasPrefix(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error starting with '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor([]string{"", "abc", "abcdef", "s3cr3t"})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "nothing to hide", expected: "nothing to hide"},
+		{input: "token=s3cr3t", expected: "token=[redacted]"},
+		{input: "abcdef and abc", expected: "[redacted] and [redacted]"},
+		{input: "s3cr3ts3cr3t", expected: "[redacted][redacted]"},
+	}
+
+	for _, tt := range tests {
+		if got := r.Redact(tt.input); got != tt.expected {
+			t.Errorf("Redact(%q): expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestRedactor_Writer(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewRedactor([]string{"hunter2"}).Writer(&buf)
+
+	line := "Hook output: password is hunter2\n"
+	n, err := w.Write([]byte(line))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if n != len(line) {
+		t.Errorf("Expected %d bytes written, got %d", len(line), n)
+	}
+	if buf.String() != "Hook output: password is [redacted]\n" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+}
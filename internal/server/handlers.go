@@ -2,18 +2,35 @@ package server
 
 import (
 	"encoding/json"
-	"sync"
+	"errors"
 	"net/http"
-	
+	"syscall"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
 	"github.com/TheRealSibasishBehera/init-go/internal/exec"
+	"github.com/TheRealSibasishBehera/init-go/internal/hooks"
+	"github.com/TheRealSibasishBehera/init-go/internal/network"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
+	"github.com/TheRealSibasishBehera/init-go/internal/secrets"
+	"github.com/TheRealSibasishBehera/init-go/internal/supervisor"
 	system "github.com/TheRealSibasishBehera/init-go/internal/system"
 	"github.com/TheRealSibasishBehera/init-go/internal/websocket"
 )
 
-func statusHandler(w http.ResponseWriter, r *http.Request) {
+// Status is the response of the /status endpoint.
+type Status struct {
+	Status       string        `json:"status"`
+	ConfigSource config.Source `json:"config_source"`
+}
+
+// StatusHandler reports that init is up and where its configuration was
+// loaded from.
+func (h *APIHandler) StatusHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status": "OK"}`))
+	if err := json.NewEncoder(w).Encode(Status{Status: "OK", ConfigSource: h.source}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
 }
 
 func sysHandler(w http.ResponseWriter, r *http.Request) {
@@ -33,8 +50,15 @@ func sysHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 type APIHandler struct {
-	waitPidMutex *sync.Mutex
-	envs         map[string]string
+	reaper     *reaper.Reaper
+	env        []string
+	supervisor *supervisor.Supervisor
+	hooks      *hooks.Runner
+	config     *config.RunConfig
+	source     config.Source
+	// redactor hides secret values in the commands of supervised
+	// processes.
+	redactor *secrets.Redactor
 }
 
 func (h *APIHandler) ExecHandler(w http.ResponseWriter, r *http.Request) {
@@ -43,13 +67,13 @@ func (h *APIHandler) ExecHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	
+
 	if len(req.Cmd) == 0 {
 		http.Error(w, "Command cannot be empty", http.StatusBadRequest)
 		return
 	}
 
-	response, err := exec.ExecuteCommand(req, h.envs, h.waitPidMutex)
+	response, err := exec.ExecuteCommand(req, h.env, h.reaper)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -63,5 +87,138 @@ func (h *APIHandler) ExecHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *APIHandler) WSExecHandler(w http.ResponseWriter, r *http.Request) {
-	websocket.HandleWSExec(w, r, h.envs, h.waitPidMutex)
+	websocket.HandleWSExec(w, r, h.env, h.reaper)
+}
+
+// AppHandler reports the main application's state, including its exit status
+// once it has terminated. Once that has been read a shutting down guest no
+// longer lingers for it.
+func (h *APIHandler) AppHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(h.redactStatus(h.supervisor.ReportApp())); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ProcessesHandler reports the state of every supervised process, in start
+// order.
+func (h *APIHandler) ProcessesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	processes := h.supervisor.Processes()
+	for i, status := range processes {
+		processes[i] = h.redactStatus(status)
+	}
+	if err := json.NewEncoder(w).Encode(processes); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// redactStatus replaces secret values in the command of a process, which
+// may have been given one on its command line.
+func (h *APIHandler) redactStatus(status supervisor.ProcessStatus) supervisor.ProcessStatus {
+	command := make([]string, len(status.Command))
+	for i, arg := range status.Command {
+		command[i] = h.redactor.Redact(arg)
+	}
+	status.Command = command
+	return status
+}
+
+// HooksHandler reports the outcome and output of every lifecycle hook run so
+// far.
+func (h *APIHandler) HooksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(h.hooks.Results()); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ConfigHandler returns the configuration init is running with, secret
+// values redacted.
+func (h *APIHandler) ConfigHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := h.config.ToJSON()
+	if err != nil {
+		http.Error(w, "Failed to encode configuration", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(data))
+}
+
+// NetworkHandler reports the guest's links, addresses, routes, neighbors
+// and listening sockets.
+func (h *APIHandler) NetworkHandler(w http.ResponseWriter, r *http.Request) {
+	state, err := network.Inspect()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// NetworkAddressHandler adds an address to an interface on POST and
+// removes it on DELETE.
+func (h *APIHandler) NetworkAddressHandler(w http.ResponseWriter, r *http.Request) {
+	var req network.AddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	addr, err := req.Parse()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		err = network.DeleteAddress(req.Interface, addr)
+	} else {
+		err = network.AddAddress(req.Interface, addr)
+	}
+	writeNetworkResult(w, err)
+}
+
+// NetworkRouteHandler adds a route on POST and removes it on DELETE. The
+// route is given as in the routes section of run.json.
+func (h *APIHandler) NetworkRouteHandler(w http.ResponseWriter, r *http.Request) {
+	var route config.RouteConfig
+	if err := json.NewDecoder(r.Body).Decode(&route); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := route.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	if r.Method == http.MethodDelete {
+		err = network.DeleteRoute(route)
+	} else {
+		err = network.AddRoute(route)
+	}
+	writeNetworkResult(w, err)
+}
+
+// writeNetworkResult answers a network change, mapping the kernel's
+// errors onto status codes.
+func writeNetworkResult(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, network.ErrUnknownInterface), errors.Is(err, syscall.ESRCH), errors.Is(err, syscall.EADDRNOTAVAIL):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, syscall.EEXIST):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
 }
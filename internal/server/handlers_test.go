@@ -3,12 +3,20 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"sync"
+	"strings"
+	"syscall"
 	"testing"
 
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
 	"github.com/TheRealSibasishBehera/init-go/internal/exec"
+	"github.com/TheRealSibasishBehera/init-go/internal/hooks"
+	"github.com/TheRealSibasishBehera/init-go/internal/network"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper/reapertest"
+	"github.com/TheRealSibasishBehera/init-go/internal/secrets"
+	"github.com/TheRealSibasishBehera/init-go/internal/supervisor"
 )
 
 func TestStatusHandler(t *testing.T) {
@@ -18,16 +26,18 @@ func TestStatusHandler(t *testing.T) {
 	}
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(statusHandler)
+	handler := &APIHandler{
+		source: config.Source{Kind: config.SourceFile, Location: config.DefaultConfigPath},
+	}
 
-	handler.ServeHTTP(rr, req)
+	handler.StatusHandler(rr, req)
 
 	if status := rr.Code; status != http.StatusOK {
 		t.Errorf("StatusHandler returned wrong status code: got %v want %v",
 			status, http.StatusOK)
 	}
 
-	expected := `{"status": "OK"}`
+	expected := `{"status":"OK","config_source":{"kind":"file","location":"/fly/run.json"}}` + "\n"
 	if rr.Body.String() != expected {
 		t.Errorf("StatusHandler returned unexpected body: got %v want %v",
 			rr.Body.String(), expected)
@@ -55,8 +65,8 @@ func TestExecHandler_Success(t *testing.T) {
 	rr := httptest.NewRecorder()
 	
 	handler := &APIHandler{
-		waitPidMutex: &sync.Mutex{},
-		envs:         map[string]string{"PATH": "/bin:/usr/bin"},
+		reaper: reapertest.Reaper(),
+		env:    []string{"PATH=/bin:/usr/bin"},
 	}
 
 	handler.ExecHandler(rr, req)
@@ -96,8 +106,8 @@ func TestExecHandler_EmptyCommand(t *testing.T) {
 	rr := httptest.NewRecorder()
 	
 	handler := &APIHandler{
-		waitPidMutex: &sync.Mutex{},
-		envs:         map[string]string{},
+		reaper: reapertest.Reaper(),
+		env:    []string{},
 	}
 
 	handler.ExecHandler(rr, req)
@@ -120,8 +130,8 @@ func TestExecHandler_InvalidJSON(t *testing.T) {
 	rr := httptest.NewRecorder()
 	
 	handler := &APIHandler{
-		waitPidMutex: &sync.Mutex{},
-		envs:         map[string]string{},
+		reaper: reapertest.Reaper(),
+		env:    []string{},
 	}
 
 	handler.ExecHandler(rr, req)
@@ -147,8 +157,8 @@ func TestExecHandler_CommandFailure(t *testing.T) {
 	rr := httptest.NewRecorder()
 	
 	handler := &APIHandler{
-		waitPidMutex: &sync.Mutex{},
-		envs:         map[string]string{"PATH": "/bin:/usr/bin"},
+		reaper: reapertest.Reaper(),
+		env:    []string{"PATH=/bin:/usr/bin"},
 	}
 
 	handler.ExecHandler(rr, req)
@@ -170,4 +180,385 @@ func TestExecHandler_CommandFailure(t *testing.T) {
 	if len(response.Stderr) == 0 {
 		t.Error("Expected stderr output for failed command")
 	}
-}
\ No newline at end of file
+}
+
+func TestAppHandler(t *testing.T) {
+	sup := supervisor.New(nil)
+	sup.Started(config.AppProcessName, []string{"/usr/bin/myapp"}, 42)
+
+	req, err := http.NewRequest("GET", "/v1/app", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler := &APIHandler{
+		env:        []string{},
+		supervisor: sup,
+		redactor:   secrets.NewRedactor(nil),
+	}
+
+	handler.AppHandler(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("AppHandler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var response supervisor.ProcessStatus
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Pid != 42 || response.State != supervisor.StateRunning {
+		t.Errorf("Expected running app with PID 42, got %+v", response)
+	}
+
+	if response.Exit != nil {
+		t.Errorf("Expected no exit status, got %+v", response.Exit)
+	}
+}
+
+func TestAppHandler_ReportsExit(t *testing.T) {
+	sup := supervisor.New(nil)
+	sup.Started(config.AppProcessName, []string{"/usr/bin/myapp"}, 42)
+	sup.Exited(42, syscall.WaitStatus(3<<8))
+
+	req, err := http.NewRequest("GET", "/v1/app", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler := &APIHandler{
+		env:        []string{},
+		supervisor: sup,
+		redactor:   secrets.NewRedactor(nil),
+	}
+
+	handler.AppHandler(rr, req)
+
+	var response supervisor.ProcessStatus
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Exit == nil || response.Exit.Code() != 3 {
+		t.Fatalf("Expected exit code 3, got %+v", response.Exit)
+	}
+
+	if !sup.WaitAppReported(0) {
+		t.Error("Expected reading the exit to end the shutdown linger")
+	}
+}
+
+func TestProcessesHandler(t *testing.T) {
+	sup := supervisor.New([]config.ProcessConfig{
+		{Name: "log-shipper", Command: []string{"vector"}},
+		{Name: config.AppProcessName, Command: []string{"/usr/bin/myapp"}, Critical: true},
+	})
+	sup.Started("log-shipper", []string{"vector"}, 41)
+
+	req, err := http.NewRequest("GET", "/v1/processes", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler := &APIHandler{
+		env:        []string{},
+		supervisor: sup,
+		redactor:   secrets.NewRedactor(nil),
+	}
+
+	handler.ProcessesHandler(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("ProcessesHandler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var response []supervisor.ProcessStatus
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(response) != 2 {
+		t.Fatalf("Expected 2 processes, got %d", len(response))
+	}
+
+	if response[0].Name != "log-shipper" || response[0].State != supervisor.StateRunning {
+		t.Errorf("Expected running log-shipper first, got %+v", response[0])
+	}
+
+	if response[1].Name != config.AppProcessName || response[1].State != supervisor.StatePending || !response[1].Critical {
+		t.Errorf("Expected pending critical app second, got %+v", response[1])
+	}
+}
+
+func TestProcessesHandler_RedactsSecrets(t *testing.T) {
+	sup := supervisor.New([]config.ProcessConfig{
+		{Name: config.AppProcessName, Command: []string{"app", "--pw=hunter2"}, Critical: true},
+	})
+	sup.Started(config.AppProcessName, []string{"app", "--pw=hunter2"}, 42)
+
+	handler := &APIHandler{
+		env:        []string{},
+		supervisor: sup,
+		redactor:   secrets.NewRedactor([]string{"hunter2"}),
+	}
+
+	for path, handle := range map[string]http.HandlerFunc{
+		"/v1/app":       handler.AppHandler,
+		"/v1/processes": handler.ProcessesHandler,
+	} {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handle(rr, req)
+
+		if body := rr.Body.String(); strings.Contains(body, "hunter2") || !strings.Contains(body, "--pw="+config.RedactedValue) {
+			t.Errorf("Expected the secret to be redacted from %s, got %s", path, body)
+		}
+	}
+
+	if status, _ := sup.Status(config.AppProcessName); status.Command[1] != "--pw=hunter2" {
+		t.Errorf("Expected the supervisor's command to be left alone, got %v", status.Command)
+	}
+}
+
+func TestHooksHandler(t *testing.T) {
+	rp := reapertest.Reaper()
+	hk := hooks.New(rp, secrets.NewRedactor(nil))
+	if err := hk.Run(config.HookPreStart, []config.HookConfig{
+		{Command: []string{"echo", "migrated"}},
+	}, nil, "", nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	req, err := http.NewRequest("GET", "/v1/hooks", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler := &APIHandler{
+		env:   []string{},
+		hooks: hk,
+	}
+
+	handler.HooksHandler(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("HooksHandler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	var response []hooks.Result
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(response) != 1 {
+		t.Fatalf("Expected 1 hook result, got %d", len(response))
+	}
+
+	if response[0].Phase != config.HookPreStart || !response[0].Succeeded() || response[0].Output != "migrated\n" {
+		t.Errorf("Expected successful preStart hook with its output, got %+v", response[0])
+	}
+}
+
+func TestHooksHandler_RedactsSecrets(t *testing.T) {
+	rp := reapertest.Reaper()
+	hk := hooks.New(rp, secrets.NewRedactor([]string{"hunter2"}))
+	if err := hk.Run(config.HookPreStart, []config.HookConfig{
+		{Command: []string{"sh", "-c", "echo password=$DB_PASSWORD"}},
+	}, []string{"DB_PASSWORD=hunter2"}, "", nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	req, err := http.NewRequest("GET", "/v1/hooks", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler := &APIHandler{
+		env:   []string{},
+		hooks: hk,
+	}
+
+	handler.HooksHandler(rr, req)
+
+	if strings.Contains(rr.Body.String(), "hunter2") {
+		t.Fatalf("Expected the secret to be redacted, got %s", rr.Body.String())
+	}
+
+	var response []hooks.Result
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(response) != 1 {
+		t.Fatalf("Expected 1 hook result, got %d", len(response))
+	}
+
+	if expected := "password=" + config.RedactedValue + "\n"; response[0].Output != expected {
+		t.Errorf("Expected output %q, got %q", expected, response[0].Output)
+	}
+}
+
+func TestConfigHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/v1/config", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+
+	handler := &APIHandler{
+		config: &config.RunConfig{
+			Hostname: "app",
+			Secrets: []config.SecretConfig{
+				{Name: "db", Value: "hunter2", Env: "DB_PASSWORD"},
+			},
+		},
+	}
+
+	handler.ConfigHandler(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("ConfigHandler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	if strings.Contains(rr.Body.String(), "hunter2") {
+		t.Errorf("Expected secret value to be redacted, got %s", rr.Body.String())
+	}
+
+	var response config.RunConfig
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Hostname != "app" || response.Secrets[0].Value != config.RedactedValue {
+		t.Errorf("Unexpected configuration: %+v", response)
+	}
+}
+
+func TestNetworkHandler(t *testing.T) {
+	req, err := http.NewRequest("GET", "/v1/network", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := &APIHandler{}
+	handler.NetworkHandler(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("NetworkHandler returned wrong status code: got %v want %v: %s",
+			status, http.StatusOK, rr.Body.String())
+	}
+
+	var response network.State
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Links == nil || response.Routes == nil || response.Neighbors == nil || response.Sockets == nil {
+		t.Errorf("Expected every section to be a list, got %s", rr.Body.String())
+	}
+}
+
+func TestNetworkChangeHandlers_BadRequest(t *testing.T) {
+	handler := &APIHandler{}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		handle   http.HandlerFunc
+		expected string
+	}{
+		{
+			name:     "Invalid address body",
+			method:   "POST",
+			path:     "/v1/network/addresses",
+			body:     "not json",
+			handle:   handler.NetworkAddressHandler,
+			expected: "Invalid request body",
+		},
+		{
+			name:     "Missing interface",
+			method:   "POST",
+			path:     "/v1/network/addresses",
+			body:     `{"address": "10.0.0.5/24"}`,
+			handle:   handler.NetworkAddressHandler,
+			expected: "interface is required",
+		},
+		{
+			name:     "Address without prefix",
+			method:   "DELETE",
+			path:     "/v1/network/addresses",
+			body:     `{"interface": "eth0", "address": "10.0.0.5"}`,
+			handle:   handler.NetworkAddressHandler,
+			expected: "invalid address 10.0.0.5, expected a CIDR",
+		},
+		{
+			name:     "Invalid route",
+			method:   "POST",
+			path:     "/v1/network/routes",
+			body:     `{"destination": "10.0.0.0/8"}`,
+			handle:   handler.NetworkRouteHandler,
+			expected: "a unicast route needs a gateway or a device",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			tt.handle(rr, req)
+
+			if status := rr.Code; status != http.StatusBadRequest {
+				t.Errorf("Expected status %v, got %v", http.StatusBadRequest, status)
+			}
+			if body := strings.TrimSpace(rr.Body.String()); body != tt.expected {
+				t.Errorf("Expected body '%s', got '%s'", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestWriteNetworkResult(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{err: nil, expected: http.StatusNoContent},
+		{err: fmt.Errorf("interface eth9: %w", network.ErrUnknownInterface), expected: http.StatusNotFound},
+		{err: fmt.Errorf("route: %w", syscall.ESRCH), expected: http.StatusNotFound},
+		{err: fmt.Errorf("interface eth0: failed to add address: %w", syscall.EEXIST), expected: http.StatusConflict},
+		{err: syscall.EPERM, expected: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		writeNetworkResult(rr, tt.err)
+		if rr.Code != tt.expected {
+			t.Errorf("Expected status %v for %v, got %v", tt.expected, tt.err, rr.Code)
+		}
+	}
+}
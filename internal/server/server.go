@@ -1,24 +1,34 @@
 package server
 
 import (
+	"net/http"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"github.com/TheRealSibasishBehera/init-go/internal/hooks"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
+	"github.com/TheRealSibasishBehera/init-go/internal/secrets"
+	"github.com/TheRealSibasishBehera/init-go/internal/supervisor"
 	mux "github.com/gorilla/mux"
 	"github.com/mdlayher/vsock"
-	"net/http"
-	"sync"
 )
 
 const (
 	VSockPort = 1000
 )
 
-func NewAPIHandler(waitPidMutex *sync.Mutex, envs map[string]string) *APIHandler {
+func NewAPIHandler(rp *reaper.Reaper, env []string, sup *supervisor.Supervisor, hk *hooks.Runner, cfg *config.RunConfig, source config.Source) *APIHandler {
 	return &APIHandler{
-		waitPidMutex: waitPidMutex,
-		envs:         envs,
+		reaper:     rp,
+		env:        env,
+		supervisor: sup,
+		hooks:      hk,
+		config:     cfg,
+		source:     source,
+		redactor:   secrets.NewRedactor(cfg.GetSecretValues()),
 	}
 }
 
-func StartVSocServer(waitPidMutex *sync.Mutex, envs map[string]string) {
+func StartVSocServer(rp *reaper.Reaper, env []string, sup *supervisor.Supervisor, hk *hooks.Runner, cfg *config.RunConfig, source config.Source) {
 	listener, err := vsock.Listen(VSockPort, nil)
 	if err != nil {
 		panic("Failed to start vsock listener: " + err.Error())
@@ -26,25 +36,32 @@ func StartVSocServer(waitPidMutex *sync.Mutex, envs map[string]string) {
 	defer listener.Close()
 
 	router := NewRouter()
-	setupRoutes(router, waitPidMutex, envs)
+	setupRoutes(router, rp, env, sup, hk, cfg, source)
 	if err := http.Serve(listener, router); err != nil {
 		panic("Failed to start HTTP server: " + err.Error())
 	}
 }
 
-func setupRoutes(r *mux.Router, waitPidMutex *sync.Mutex, envs map[string]string) {
-	r.HandleFunc("/status", statusHandler).Methods("GET")
+func setupRoutes(r *mux.Router, rp *reaper.Reaper, env []string, sup *supervisor.Supervisor, hk *hooks.Runner, cfg *config.RunConfig, source config.Source) {
+	handler := NewAPIHandler(rp, env, sup, hk, cfg, source)
+	r.HandleFunc("/status", handler.StatusHandler).Methods("GET")
 
 	v1 := r.PathPrefix("/v1").Subrouter()
-	setupAPIRoutes(v1, waitPidMutex, envs)
+	setupAPIRoutes(v1, handler)
 }
 
-func setupAPIRoutes(r *mux.Router, waitPidMutex *sync.Mutex, envs map[string]string) {
-	handler := NewAPIHandler(waitPidMutex, envs)
+func setupAPIRoutes(r *mux.Router, handler *APIHandler) {
 
 	r.HandleFunc("/sysinfo", sysHandler).Methods("GET")
 	r.HandleFunc("/exec", handler.ExecHandler).Methods("POST")
 	r.HandleFunc("/ws/exec", handler.WSExecHandler).Methods("GET")
+	r.HandleFunc("/app", handler.AppHandler).Methods("GET")
+	r.HandleFunc("/processes", handler.ProcessesHandler).Methods("GET")
+	r.HandleFunc("/hooks", handler.HooksHandler).Methods("GET")
+	r.HandleFunc("/config", handler.ConfigHandler).Methods("GET")
+	r.HandleFunc("/network", handler.NetworkHandler).Methods("GET")
+	r.HandleFunc("/network/addresses", handler.NetworkAddressHandler).Methods("POST", "DELETE")
+	r.HandleFunc("/network/routes", handler.NetworkRouteHandler).Methods("POST", "DELETE")
 }
 
 func NewRouter() *mux.Router {
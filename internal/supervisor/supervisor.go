@@ -0,0 +1,277 @@
+package supervisor
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+)
+
+type State string
+
+const (
+	StatePending    State = "pending"
+	StateRunning    State = "running"
+	StateRestarting State = "restarting"
+	StateExited     State = "exited"
+)
+
+// ExitStatus describes how a supervised process terminated.
+type ExitStatus struct {
+	ExitCode   *int      `json:"exit_code"`
+	ExitSignal *int      `json:"exit_signal"`
+	Reason     string    `json:"reason"`
+	ExitedAt   time.Time `json:"exited_at"`
+}
+
+// ProcessStatus is the lifecycle state of a supervised process. Exit holds
+// the most recent exit and is kept across restarts.
+type ProcessStatus struct {
+	Name      string      `json:"name"`
+	Command   []string    `json:"command"`
+	Critical  bool        `json:"critical"`
+	Pid       int         `json:"pid"`
+	State     State       `json:"state"`
+	StartedAt time.Time   `json:"started_at"`
+	Restarts  int         `json:"restarts"`
+	Exit      *ExitStatus `json:"exit,omitempty"`
+}
+
+// Supervisor tracks the processes started by init so that it can tell their
+// exits apart from other reaped children and report them over the API.
+type Supervisor struct {
+	mu        sync.RWMutex
+	processes []*ProcessStatus
+	// reported is the application exit last handed out by ReportApp;
+	// reports is closed and replaced whenever it changes.
+	reported *ExitStatus
+	reports  chan struct{}
+}
+
+// New creates a Supervisor tracking processes in the given order, all of
+// them pending until Started is called.
+func New(processes []config.ProcessConfig) *Supervisor {
+	s := &Supervisor{reports: make(chan struct{})}
+	for _, p := range processes {
+		s.processes = append(s.processes, &ProcessStatus{
+			Name:     p.Name,
+			Command:  p.Command,
+			Critical: p.Critical,
+			State:    StatePending,
+		})
+	}
+	return s
+}
+
+func (s *Supervisor) find(name string) *ProcessStatus {
+	for _, p := range s.processes {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Started records that the named process is running as pid.
+func (s *Supervisor) Started(name string, command []string, pid int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.find(name)
+	if p == nil {
+		p = &ProcessStatus{Name: name}
+		s.processes = append(s.processes, p)
+	}
+	p.Command = command
+	p.Pid = pid
+	p.State = StateRunning
+	p.StartedAt = time.Now()
+}
+
+// NextRestart reports whether the named process should be relaunched after
+// exiting under restart and, if so, marks it as restarting and returns the
+// backoff to wait first. A process that ran for restart.GetResetAfter() or
+// longer starts over with no restarts.
+func (s *Supervisor) NextRestart(name string, restart config.RestartConfig) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.find(name)
+	if p == nil || p.State != StateExited || p.Exit == nil {
+		return 0, false
+	}
+
+	switch restart.Policy {
+	case config.RestartAlways:
+	case config.RestartOnFailure:
+		if p.Exit.Succeeded() {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if p.Exit.ExitedAt.Sub(p.StartedAt) >= restart.GetResetAfter() {
+		p.Restarts = 0
+	}
+	if restart.MaxRetries > 0 && p.Restarts >= restart.MaxRetries {
+		return 0, false
+	}
+
+	delay := restart.Backoff(p.Restarts)
+	p.Restarts++
+	p.State = StateRestarting
+	return delay, true
+}
+
+// Exited records the wait status of a reaped child. It returns the name of
+// the supervised process pid belonged to, if any.
+func (s *Supervisor) Exited(pid int, status syscall.WaitStatus) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.processes {
+		if p.Pid == 0 || p.Pid != pid || p.State != StateRunning {
+			continue
+		}
+
+		exit := NewExitStatus(status)
+		p.State = StateExited
+		p.Exit = &exit
+		return p.Name, true
+	}
+	return "", false
+}
+
+// FailedToStart records that launching the named process failed, as an
+// exit without a code so that its restart policy applies as to a failure.
+func (s *Supervisor) FailedToStart(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.find(name)
+	if p == nil {
+		return
+	}
+	now := time.Now()
+	p.Pid = 0
+	p.State = StateExited
+	p.StartedAt = now
+	p.Exit = &ExitStatus{Reason: fmt.Sprintf("failed to start: %v", err), ExitedAt: now}
+}
+
+// Status returns a snapshot of the named process's state.
+func (s *Supervisor) Status(name string) (ProcessStatus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p := s.find(name)
+	if p == nil {
+		return ProcessStatus{}, false
+	}
+	return p.snapshot(), true
+}
+
+// App returns a snapshot of the main application's state.
+func (s *Supervisor) App() ProcessStatus {
+	status, _ := s.Status(config.AppProcessName)
+	return status
+}
+
+// ReportApp returns a snapshot of the main application's state like App and
+// records that its exit, if it has exited, was handed out. See
+// WaitAppReported.
+func (s *Supervisor) ReportApp() ProcessStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.find(config.AppProcessName)
+	if p == nil {
+		return ProcessStatus{}
+	}
+	if p.State == StateExited && p.Exit != s.reported {
+		s.reported = p.Exit
+		close(s.reports)
+		s.reports = make(chan struct{})
+	}
+	return p.snapshot()
+}
+
+// WaitAppReported waits up to timeout for ReportApp to hand out the
+// application's latest exit. It reports whether that happened.
+func (s *Supervisor) WaitAppReported(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		s.mu.RLock()
+		p := s.find(config.AppProcessName)
+		reported := p != nil && p.State == StateExited && p.Exit == s.reported
+		reports := s.reports
+		s.mu.RUnlock()
+
+		if reported {
+			return true
+		}
+		select {
+		case <-reports:
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+// Processes returns a snapshot of every supervised process in start order.
+func (s *Supervisor) Processes() []ProcessStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]ProcessStatus, 0, len(s.processes))
+	for _, p := range s.processes {
+		statuses = append(statuses, p.snapshot())
+	}
+	return statuses
+}
+
+func (p *ProcessStatus) snapshot() ProcessStatus {
+	status := *p
+	if p.Exit != nil {
+		exit := *p.Exit
+		status.Exit = &exit
+	}
+	return status
+}
+
+// NewExitStatus converts a wait status into an ExitStatus.
+func NewExitStatus(status syscall.WaitStatus) ExitStatus {
+	exit := ExitStatus{ExitedAt: time.Now()}
+	if status.Signaled() {
+		signal := int(status.Signal())
+		exit.ExitSignal = &signal
+		exit.Reason = fmt.Sprintf("killed by signal %s", status.Signal())
+	} else if status.Exited() {
+		code := status.ExitStatus()
+		exit.ExitCode = &code
+		exit.Reason = fmt.Sprintf("exited with code %d", code)
+	}
+	return exit
+}
+
+// Succeeded reports whether the process exited normally with code 0.
+func (e ExitStatus) Succeeded() bool {
+	return e.ExitCode != nil && *e.ExitCode == 0
+}
+
+// Code returns the status init should propagate for this exit, following
+// the shell convention of 128+signal for signaled processes.
+func (e ExitStatus) Code() int {
+	if e.ExitCode != nil {
+		return *e.ExitCode
+	}
+	if e.ExitSignal != nil {
+		return 128 + *e.ExitSignal
+	}
+	return 1
+}
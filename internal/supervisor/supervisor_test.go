@@ -0,0 +1,283 @@
+package supervisor
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+)
+
+func waitStatusOf(t *testing.T, name string, args ...string) syscall.WaitStatus {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	_ = cmd.Run()
+	if cmd.ProcessState == nil {
+		t.Fatalf("Command %s did not run", name)
+	}
+	return cmd.ProcessState.Sys().(syscall.WaitStatus)
+}
+
+func newTestSupervisor() *Supervisor {
+	return New([]config.ProcessConfig{
+		{Name: "sidecar", Command: []string{"sidecar"}},
+		{Name: config.AppProcessName, Command: []string{"app"}, Critical: true},
+	})
+}
+
+func TestSupervisor_New(t *testing.T) {
+	sup := newTestSupervisor()
+
+	processes := sup.Processes()
+	if len(processes) != 2 {
+		t.Fatalf("Expected 2 processes, got %d", len(processes))
+	}
+
+	if processes[0].Name != "sidecar" || processes[1].Name != config.AppProcessName {
+		t.Errorf("Expected processes in configured order, got %+v", processes)
+	}
+
+	for _, p := range processes {
+		if p.State != StatePending {
+			t.Errorf("Expected %s to be pending, got %s", p.Name, p.State)
+		}
+	}
+
+	if !sup.App().Critical {
+		t.Error("Expected application to be critical")
+	}
+}
+
+func TestSupervisor_ExitedApp(t *testing.T) {
+	sup := newTestSupervisor()
+	sup.Started(config.AppProcessName, []string{"app"}, 42)
+
+	status := waitStatusOf(t, "sh", "-c", "exit 3")
+	name, ok := sup.Exited(42, status)
+	if !ok || name != config.AppProcessName {
+		t.Fatalf("Expected PID 42 to be recognised as the application, got %q", name)
+	}
+
+	app := sup.App()
+	if app.State != StateExited {
+		t.Errorf("Expected state %s, got %s", StateExited, app.State)
+	}
+	if app.Exit == nil || app.Exit.ExitCode == nil || *app.Exit.ExitCode != 3 {
+		t.Fatalf("Expected exit code 3, got %+v", app.Exit)
+	}
+	if app.Exit.Code() != 3 {
+		t.Errorf("Expected propagated code 3, got %d", app.Exit.Code())
+	}
+}
+
+func TestSupervisor_ExitedSidecar(t *testing.T) {
+	sup := newTestSupervisor()
+	sup.Started(config.AppProcessName, []string{"app"}, 42)
+	sup.Started("sidecar", []string{"sidecar"}, 43)
+
+	name, ok := sup.Exited(43, waitStatusOf(t, "true"))
+	if !ok || name != "sidecar" {
+		t.Fatalf("Expected PID 43 to be recognised as sidecar, got %q", name)
+	}
+
+	if sup.App().State != StateRunning {
+		t.Errorf("Expected application to still be running")
+	}
+}
+
+func TestSupervisor_ExitedOtherChild(t *testing.T) {
+	sup := newTestSupervisor()
+	sup.Started(config.AppProcessName, []string{"app"}, 42)
+
+	if _, ok := sup.Exited(44, waitStatusOf(t, "true")); ok {
+		t.Error("Expected PID 44 not to be recognised as a supervised process")
+	}
+}
+
+func TestSupervisor_ExitedBeforeStart(t *testing.T) {
+	sup := newTestSupervisor()
+
+	if _, ok := sup.Exited(0, waitStatusOf(t, "true")); ok {
+		t.Error("Expected no process match before Started")
+	}
+}
+
+func TestSupervisor_WaitAppReported(t *testing.T) {
+	sup := newTestSupervisor()
+	sup.Started(config.AppProcessName, []string{"app"}, 42)
+
+	// a running application has no final status to report
+	sup.ReportApp()
+	if sup.WaitAppReported(10 * time.Millisecond) {
+		t.Fatal("Expected no report while the application is running")
+	}
+
+	sup.Exited(42, waitStatusOf(t, "true"))
+	if sup.WaitAppReported(10 * time.Millisecond) {
+		t.Fatal("Expected no report before ReportApp")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		sup.ReportApp()
+	}()
+	if !sup.WaitAppReported(5 * time.Second) {
+		t.Fatal("Expected ReportApp to end the wait")
+	}
+
+	// a restart makes the reported exit stale
+	sup.Started(config.AppProcessName, []string{"app"}, 43)
+	sup.Exited(43, waitStatusOf(t, "true"))
+	if sup.WaitAppReported(10 * time.Millisecond) {
+		t.Error("Expected the exit of the restarted application to be unreported")
+	}
+}
+
+func TestNewExitStatus_Signaled(t *testing.T) {
+	exit := NewExitStatus(waitStatusOf(t, "sh", "-c", "kill -TERM $$"))
+
+	if exit.ExitCode != nil {
+		t.Errorf("Expected no exit code, got %d", *exit.ExitCode)
+	}
+	if exit.ExitSignal == nil || *exit.ExitSignal != int(syscall.SIGTERM) {
+		t.Fatalf("Expected signal %d, got %v", syscall.SIGTERM, exit.ExitSignal)
+	}
+	if exit.Code() != 128+int(syscall.SIGTERM) {
+		t.Errorf("Expected propagated code %d, got %d", 128+int(syscall.SIGTERM), exit.Code())
+	}
+}
+
+func TestSupervisor_NextRestart(t *testing.T) {
+	tests := []struct {
+		name     string
+		restart  config.RestartConfig
+		command  string
+		restarts int
+		expected bool
+	}{
+		{
+			name:     "No policy",
+			restart:  config.RestartConfig{Policy: config.RestartNo},
+			command:  "exit 1",
+			expected: false,
+		},
+		{
+			name:     "On failure after failure",
+			restart:  config.RestartConfig{Policy: config.RestartOnFailure},
+			command:  "exit 1",
+			expected: true,
+		},
+		{
+			name:     "On failure after success",
+			restart:  config.RestartConfig{Policy: config.RestartOnFailure},
+			command:  "exit 0",
+			expected: false,
+		},
+		{
+			name:     "Always after success",
+			restart:  config.RestartConfig{Policy: config.RestartAlways},
+			command:  "exit 0",
+			expected: true,
+		},
+		{
+			name:     "Retries exhausted",
+			restart:  config.RestartConfig{Policy: config.RestartAlways, MaxRetries: 2},
+			command:  "exit 1",
+			restarts: 2,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sup := newTestSupervisor()
+			sup.find("sidecar").Restarts = tt.restarts
+			sup.Started("sidecar", []string{"sidecar"}, 42)
+			sup.Exited(42, waitStatusOf(t, "sh", "-c", tt.command))
+
+			_, ok := sup.NextRestart("sidecar", tt.restart)
+			if ok != tt.expected {
+				t.Fatalf("Expected restart %v, got %v", tt.expected, ok)
+			}
+
+			status, _ := sup.Status("sidecar")
+			if ok && (status.State != StateRestarting || status.Restarts != tt.restarts+1) {
+				t.Errorf("Expected restarting with %d restarts, got %+v", tt.restarts+1, status)
+			}
+			if !ok && status.State != StateExited {
+				t.Errorf("Expected state %s, got %s", StateExited, status.State)
+			}
+		})
+	}
+}
+
+func TestSupervisor_NextRestartResetsAfterStableRun(t *testing.T) {
+	sup := newTestSupervisor()
+	restart := config.RestartConfig{Policy: config.RestartAlways, MaxRetries: 2, InitialBackoff: 1}
+
+	for pid := 42; pid < 44; pid++ {
+		sup.Started("sidecar", []string{"sidecar"}, pid)
+		sup.Exited(pid, waitStatusOf(t, "false"))
+		if _, ok := sup.NextRestart("sidecar", restart); !ok {
+			t.Fatalf("Expected restart of PID %d", pid)
+		}
+	}
+
+	// the third exit comes after a run longer than the reset window
+	sup.Started("sidecar", []string{"sidecar"}, 44)
+	sup.find("sidecar").StartedAt = time.Now().Add(-config.DefaultResetAfter)
+	sup.Exited(44, waitStatusOf(t, "false"))
+
+	delay, ok := sup.NextRestart("sidecar", restart)
+	if !ok {
+		t.Fatal("Expected a restart after a stable run")
+	}
+	if delay != time.Second {
+		t.Errorf("Expected the initial backoff of 1s, got %s", delay)
+	}
+	if status, _ := sup.Status("sidecar"); status.Restarts != 1 {
+		t.Errorf("Expected 1 restart, got %d", status.Restarts)
+	}
+}
+
+func TestSupervisor_RestartKeepsExit(t *testing.T) {
+	sup := newTestSupervisor()
+	sup.Started(config.AppProcessName, []string{"app"}, 42)
+	sup.Exited(42, waitStatusOf(t, "sh", "-c", "exit 7"))
+	sup.NextRestart(config.AppProcessName, config.RestartConfig{Policy: config.RestartAlways})
+	sup.Started(config.AppProcessName, []string{"app"}, 43)
+
+	status := sup.App()
+	if status.Pid != 43 || status.State != StateRunning {
+		t.Errorf("Expected running app with PID 43, got %+v", status)
+	}
+	if status.Exit == nil || status.Exit.Reason != "exited with code 7" {
+		t.Errorf("Expected last exit reason to be kept, got %+v", status.Exit)
+	}
+}
+
+func TestSupervisor_FailedToStart(t *testing.T) {
+	sup := newTestSupervisor()
+	restart := config.RestartConfig{Policy: config.RestartOnFailure, MaxRetries: 1}
+
+	sup.Started("sidecar", []string{"sidecar"}, 42)
+	sup.Exited(42, waitStatusOf(t, "false"))
+	if _, ok := sup.NextRestart("sidecar", restart); !ok {
+		t.Fatal("Expected a restart")
+	}
+
+	sup.FailedToStart("sidecar", errors.New("no such file or directory"))
+
+	status, _ := sup.Status("sidecar")
+	if status.State != StateExited || status.Pid != 0 {
+		t.Errorf("Expected an exited process without a PID, got %+v", status)
+	}
+	if status.Exit == nil || status.Exit.Reason != "failed to start: no such file or directory" || status.Exit.Succeeded() {
+		t.Errorf("Expected a failed start to be recorded, got %+v", status.Exit)
+	}
+	if _, ok := sup.NextRestart("sidecar", restart); ok {
+		t.Error("Expected the failed start to count against the retries")
+	}
+}
@@ -0,0 +1,62 @@
+package system
+
+import (
+	"os"
+	"strings"
+)
+
+// KernelCmdlinePath is where the kernel exposes its command line.
+const KernelCmdlinePath = "/proc/cmdline"
+
+// KernelParams returns the parameters on the kernel command line. Flags
+// without a value map to the empty string.
+func KernelParams() (map[string]string, error) {
+	data, err := os.ReadFile(KernelCmdlinePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKernelCmdline(string(data)), nil
+}
+
+// KernelParam returns the value of a kernel command line parameter. It
+// reports false if the parameter is not set or the command line cannot be
+// read.
+func KernelParam(name string) (string, bool) {
+	params, err := KernelParams()
+	if err != nil {
+		return "", false
+	}
+	value, ok := params[name]
+	return value, ok
+}
+
+// ParseKernelCmdline splits a kernel command line into its parameters.
+// Double quotes group spaces into a value as they do for the kernel, e.g.
+// param="a b". Later parameters override earlier ones.
+func ParseKernelCmdline(cmdline string) map[string]string {
+	params := make(map[string]string)
+
+	var word strings.Builder
+	inQuotes := false
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+		key, value, _ := strings.Cut(word.String(), "=")
+		params[key] = value
+		word.Reset()
+	}
+
+	for _, r := range cmdline {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return params
+}
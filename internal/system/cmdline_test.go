@@ -0,0 +1,62 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKernelCmdline(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmdline  string
+		expected map[string]string
+	}{
+		{
+			name:     "Empty",
+			cmdline:  "\n",
+			expected: map[string]string{},
+		},
+		{
+			name:    "Flags and values",
+			cmdline: "console=ttyS0 reboot=k panic=1 quiet init.rescue=1\n",
+			expected: map[string]string{
+				"console":     "ttyS0",
+				"reboot":      "k",
+				"panic":       "1",
+				"quiet":       "",
+				"init.rescue": "1",
+			},
+		},
+		{
+			name:    "Quoted value",
+			cmdline: `init.shell="/bin/busybox sh" ro`,
+			expected: map[string]string{
+				"init.shell": "/bin/busybox sh",
+				"ro":         "",
+			},
+		},
+		{
+			name:    "Value with equals sign",
+			cmdline: "root=PARTUUID=abcd-01",
+			expected: map[string]string{
+				"root": "PARTUUID=abcd-01",
+			},
+		},
+		{
+			name:    "Last value wins",
+			cmdline: "init.rescue=1  init.rescue=0",
+			expected: map[string]string{
+				"init.rescue": "0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := ParseKernelCmdline(tt.cmdline)
+			if !reflect.DeepEqual(params, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, params)
+			}
+		})
+	}
+}
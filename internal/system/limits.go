@@ -0,0 +1,22 @@
+//go:build linux
+
+package system
+
+import (
+	"syscall"
+)
+
+// SetRlimit sets the resource limit with the number resource, see
+// config.ParseRlimit, on init, from where every process started afterwards
+// inherits it.
+func SetRlimit(resource int, soft, hard uint64) error {
+	// syscall.Setrlimit rather than unix.Setrlimit, so that the runtime
+	// stops restoring its original RLIMIT_NOFILE in child processes
+	return syscall.Setrlimit(resource, &syscall.Rlimit{Cur: soft, Max: hard})
+}
+
+// SetAmbientCaps makes a process started with attr keep caps as ambient
+// capabilities after switching to its user.
+func SetAmbientCaps(attr *syscall.SysProcAttr, caps []uintptr) {
+	attr.AmbientCaps = caps
+}
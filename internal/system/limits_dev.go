@@ -0,0 +1,19 @@
+//go:build !linux
+
+package system
+
+import (
+	"log"
+	"syscall"
+)
+
+// SetRlimit is a development stub for non-Linux platforms
+func SetRlimit(resource int, soft, hard uint64) error {
+	log.Printf("[DEV] Would set resource limit %d to %d/%d", resource, soft, hard)
+	return nil
+}
+
+// SetAmbientCaps is a development stub for non-Linux platforms
+func SetAmbientCaps(attr *syscall.SysProcAttr, caps []uintptr) {
+	log.Printf("[DEV] Would grant ambient capabilities %v", caps)
+}
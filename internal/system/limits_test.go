@@ -0,0 +1,57 @@
+//go:build linux
+
+package system
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"golang.org/x/sys/unix"
+)
+
+func TestSetRlimit(t *testing.T) {
+	var current syscall.Rlimit
+	if err := syscall.Getrlimit(unix.RLIMIT_CORE, &current); err != nil {
+		t.Fatalf("Getrlimit failed: %v", err)
+	}
+	defer syscall.Setrlimit(unix.RLIMIT_CORE, &current)
+
+	// lowering the soft limit is always allowed
+	if err := SetRlimit(unix.RLIMIT_CORE, 0, current.Max); err != nil {
+		t.Fatalf("SetRlimit failed: %v", err)
+	}
+	var updated syscall.Rlimit
+	if err := syscall.Getrlimit(unix.RLIMIT_CORE, &updated); err != nil {
+		t.Fatalf("Getrlimit failed: %v", err)
+	}
+	if updated.Cur != 0 || updated.Max != current.Max {
+		t.Errorf("Expected RLIMIT_CORE 0/%d, got %d/%d", current.Max, updated.Cur, updated.Max)
+	}
+}
+
+func TestParseRlimit_MatchesKernel(t *testing.T) {
+	resources := map[string]int{
+		"RLIMIT_AS":         unix.RLIMIT_AS,
+		"RLIMIT_CORE":       unix.RLIMIT_CORE,
+		"RLIMIT_CPU":        unix.RLIMIT_CPU,
+		"RLIMIT_DATA":       unix.RLIMIT_DATA,
+		"RLIMIT_FSIZE":      unix.RLIMIT_FSIZE,
+		"RLIMIT_LOCKS":      unix.RLIMIT_LOCKS,
+		"RLIMIT_MEMLOCK":    unix.RLIMIT_MEMLOCK,
+		"RLIMIT_MSGQUEUE":   unix.RLIMIT_MSGQUEUE,
+		"RLIMIT_NICE":       unix.RLIMIT_NICE,
+		"RLIMIT_NOFILE":     unix.RLIMIT_NOFILE,
+		"RLIMIT_NPROC":      unix.RLIMIT_NPROC,
+		"RLIMIT_RSS":        unix.RLIMIT_RSS,
+		"RLIMIT_RTPRIO":     unix.RLIMIT_RTPRIO,
+		"RLIMIT_RTTIME":     unix.RLIMIT_RTTIME,
+		"RLIMIT_SIGPENDING": unix.RLIMIT_SIGPENDING,
+		"RLIMIT_STACK":      unix.RLIMIT_STACK,
+	}
+	for name, expected := range resources {
+		if resource, err := config.ParseRlimit(name); err != nil || resource != expected {
+			t.Errorf("ParseRlimit(%s): expected %d, got %d (%v)", name, expected, resource, err)
+		}
+	}
+}
@@ -4,56 +4,46 @@ package system
 
 import (
 	"fmt"
-	config "github.com/TheRealSibasishBehera/init-go/internal/config"
 	"golang.org/x/sys/unix"
 	"os"
-	"os/exec"
 )
 
-func MountEssential(config *config.RunConfig) {
-	if err := mount("none", "/proc", "proc", CommonMountFlags, ""); err != nil {
-		panic(fmt.Sprintf("failed to mount /proc: %v", err))
+// MountEssential mounts the pseudo filesystems every guest needs. It runs
+// before the configuration is loaded so that /proc and /dev are available
+// to read it.
+func MountEssential() error {
+	mounts := []struct {
+		target, fstype string
+		flags          uintptr
+		data           string
+	}{
+		{"/proc", "proc", CommonMountFlags, ""},
+		{"/dev/pts", "devpts", MS_NOSUID | MS_NOEXEC, "mode=0620,gid=5,ptmxmode=666"},
+		{"/dev/mqueue", "mqueue", CommonMountFlags, ""},
+		{"/dev/shm", "tmpfs", MS_NOSUID | MS_NODEV, ""},
+		{"/sys", "sysfs", CommonMountFlags, ""},
+		{"/sys/fs/cgroup", "cgroup", CommonMountFlags, ""},
 	}
-	if err := mount("none", "/dev/pts", "devpts", MS_NOSUID|MS_NOEXEC, "mode=0620,gid=5,ptmxmode=666"); err != nil {
-		panic(fmt.Sprintf("failed to mount /dev/pts: %v", err))
-	}
-	if err := mount("none", "/dev/mqueue", "mqueue", CommonMountFlags, ""); err != nil {
-		panic(fmt.Sprintf("failed to mount /dev/mqueue: %v", err))
-	}
-	if err := mount("none", "/dev/shm", "tmpfs", MS_NOSUID|MS_NODEV, ""); err != nil {
-		panic(fmt.Sprintf("failed to mount /dev/shm: %v", err))
-	}
-	if err := mount("none", "/sys", "sysfs", CommonMountFlags, ""); err != nil {
-		panic(fmt.Sprintf("failed to mount /sys: %v", err))
-	}
-	if err := mount("none", "/sys/fs/cgroup", "cgroup", CommonMountFlags, ""); err != nil {
-		panic(fmt.Sprintf("failed to mount /sys/fs/cgroup: %v", err))
-	}
-
-	setHostname(config.Hostname)
-
-	cmd := exec.Command("/bin/sh")
-
-	// cmd.Env = append(cmd.Env, paths)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	err := cmd.Start()
-	if err != nil {
-		panic(fmt.Sprintf("could not start /bin/sh, error: %s", err))
+	for _, m := range mounts {
+		if err := mount("none", m.target, m.fstype, m.flags, m.data); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	err = cmd.Wait()
-	if err != nil {
-		panic(fmt.Sprintf("could not wait for /bin/sh, error: %s", err))
-	}
+// MountSecrets mounts a tmpfs for secret files at target. Only root can
+// list it; the files in it carry their own owner and mode.
+func MountSecrets(target string) error {
+	return mount("tmpfs", target, "tmpfs", CommonMountFlags, "mode=0711")
 }
-func setHostname(hostname string) {
-	err := unix.Sethostname([]byte(hostname))
-	if err != nil {
-		panic(fmt.Sprintf("cannot set hostname to %s, error: %s", hostname, err))
+
+// SetHostname sets the guest's hostname.
+func SetHostname(hostname string) error {
+	if err := unix.Sethostname([]byte(hostname)); err != nil {
+		return fmt.Errorf("cannot set hostname to %s: %w", hostname, err)
 	}
+	return nil
 }
 
 const (
@@ -77,3 +67,12 @@ func mount(source, target, filesystemtype string, flags uintptr, data string) er
 	}
 	return nil
 }
+
+// BindMount mounts the file or directory source over target, which must
+// exist.
+func BindMount(source, target string) error {
+	if err := unix.Mount(source, target, "", unix.MS_BIND, ""); err != nil {
+		return fmt.Errorf("failed to bind mount %s to %s: %w", source, target, err)
+	}
+	return nil
+}
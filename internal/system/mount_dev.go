@@ -4,11 +4,28 @@ package system
 
 import (
 	"log"
-	config "github.com/TheRealSibasishBehera/init-go/internal/config"
 )
 
 // MountEssential is a development stub for non-Linux platforms
-func MountEssential(cfg *config.RunConfig) {
+func MountEssential() error {
 	log.Println("[DEV] Skipping mount operations - not running on Linux")
-	log.Printf("[DEV] Would mount essential filesystems for hostname: %s", cfg.Hostname)
-}
\ No newline at end of file
+	return nil
+}
+
+// MountSecrets is a development stub for non-Linux platforms
+func MountSecrets(target string) error {
+	log.Printf("[DEV] Would mount a secrets tmpfs at %s", target)
+	return nil
+}
+
+// BindMount is a development stub for non-Linux platforms
+func BindMount(source, target string) error {
+	log.Printf("[DEV] Would bind mount %s to %s", source, target)
+	return nil
+}
+
+// SetHostname is a development stub for non-Linux platforms
+func SetHostname(hostname string) error {
+	log.Printf("[DEV] Would set hostname to %s", hostname)
+	return nil
+}
@@ -0,0 +1,125 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Process is a single entry of the process table read from /proc.
+type Process struct {
+	Pid  int    `json:"pid"`
+	PPid int    `json:"ppid"`
+	Pgid int    `json:"pgid"`
+	Comm string `json:"comm"`
+}
+
+const procRoot = "/proc"
+
+// ListProcesses reads every process from /proc.
+func ListProcesses() ([]Process, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", procRoot, err)
+	}
+
+	processes := make([]Process, 0, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "stat"))
+		if err != nil {
+			// the process exited while we were listing
+			continue
+		}
+
+		process, err := parseProcStat(pid, string(data))
+		if err != nil {
+			return nil, err
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// parseProcStat parses /proc/[pid]/stat. The comm field is wrapped in
+// parentheses and may itself contain spaces or parentheses, so the remaining
+// fields are located from the last closing parenthesis.
+func parseProcStat(pid int, stat string) (Process, error) {
+	open := strings.IndexByte(stat, '(')
+	end := strings.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return Process{}, fmt.Errorf("failed to parse stat of process %d", pid)
+	}
+
+	// fields after comm: state ppid pgrp ...
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 3 {
+		return Process{}, fmt.Errorf("failed to parse stat of process %d", pid)
+	}
+
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Process{}, fmt.Errorf("failed to parse ppid of process %d: %v", pid, err)
+	}
+	pgid, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return Process{}, fmt.Errorf("failed to parse pgid of process %d: %v", pid, err)
+	}
+
+	return Process{
+		Pid:  pid,
+		PPid: ppid,
+		Pgid: pgid,
+		Comm: stat[open+1 : end],
+	}, nil
+}
+
+// Descendants returns every process descended from pid or sharing its
+// process group, excluding pid itself. Children orphaned by pid's exit are
+// reparented to init, so the process group is what still ties them to it.
+func Descendants(pid int) ([]Process, error) {
+	processes, err := ListProcesses()
+	if err != nil {
+		return nil, err
+	}
+	return descendantsOf(pid, processes), nil
+}
+
+func descendantsOf(pid int, processes []Process) []Process {
+	parents := make(map[int]int, len(processes))
+	for _, p := range processes {
+		parents[p.Pid] = p.PPid
+	}
+
+	var result []Process
+	for _, p := range processes {
+		if p.Pid == pid {
+			continue
+		}
+		if p.Pgid == pid || hasAncestor(p.Pid, pid, parents) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func hasAncestor(pid, ancestor int, parents map[int]int) bool {
+	// bounded by the table size to guard against cycles from pid reuse
+	for i := 0; i < len(parents); i++ {
+		parent, ok := parents[pid]
+		if !ok || parent == 0 {
+			return false
+		}
+		if parent == ancestor {
+			return true
+		}
+		pid = parent
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package system
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParseProcStat(t *testing.T) {
+	stat := "1234 (my (weird) app) S 1 1234 1234 0 -1 4194560 100 0 0 0"
+
+	process, err := parseProcStat(1234, stat)
+	if err != nil {
+		t.Fatalf("parseProcStat failed: %v", err)
+	}
+
+	if process.Comm != "my (weird) app" {
+		t.Errorf("Expected comm 'my (weird) app', got '%s'", process.Comm)
+	}
+
+	if process.PPid != 1 || process.Pgid != 1234 {
+		t.Errorf("Expected ppid 1 and pgid 1234, got %d and %d", process.PPid, process.Pgid)
+	}
+}
+
+func TestParseProcStat_Invalid(t *testing.T) {
+	if _, err := parseProcStat(1, "garbage"); err == nil {
+		t.Error("Expected error for malformed stat, got nil")
+	}
+}
+
+func TestDescendantsOf(t *testing.T) {
+	processes := []Process{
+		{Pid: 1, PPid: 0, Pgid: 1},
+		{Pid: 10, PPid: 1, Pgid: 10},
+		{Pid: 11, PPid: 10, Pgid: 10},
+		{Pid: 12, PPid: 11, Pgid: 12},
+		{Pid: 13, PPid: 1, Pgid: 10},
+		{Pid: 20, PPid: 1, Pgid: 20},
+	}
+
+	result := descendantsOf(10, processes)
+
+	found := make(map[int]bool)
+	for _, p := range result {
+		found[p.Pid] = true
+	}
+
+	for _, pid := range []int{11, 12, 13} {
+		if !found[pid] {
+			t.Errorf("Expected process %d to be a descendant", pid)
+		}
+	}
+
+	if found[10] || found[20] || found[1] {
+		t.Errorf("Unexpected descendants: %v", result)
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "sleep 10 & wait")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start command: %v", err)
+	}
+	defer func() {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		cmd.Wait()
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		descendants, err := Descendants(cmd.Process.Pid)
+		if err != nil {
+			t.Skipf("Descendants failed (likely non-Linux platform): %v", err)
+		}
+		for _, p := range descendants {
+			if p.Comm == "sleep" {
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected sleep among descendants, got %v", descendants)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build linux
+
+package system
+
+import (
+	"fmt"
+
+	"github.com/TheRealSibasishBehera/init-go/internal/config"
+	"golang.org/x/sys/unix"
+)
+
+// Shutdown flushes filesystem buffers and reboots the guest for
+// config.ShutdownReboot or powers it off otherwise. On success it does not
+// return.
+func Shutdown(action string) error {
+	unix.Sync()
+
+	cmd := unix.LINUX_REBOOT_CMD_POWER_OFF
+	if action == config.ShutdownReboot {
+		cmd = unix.LINUX_REBOOT_CMD_RESTART
+	}
+
+	if err := unix.Reboot(cmd); err != nil {
+		return fmt.Errorf("reboot(2) with action %s failed: %w", action, err)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package system
+
+import "log"
+
+// Shutdown is a development stub for non-Linux platforms
+func Shutdown(action string) error {
+	log.Printf("[DEV] Would %s the guest", action)
+	return nil
+}
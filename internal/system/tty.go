@@ -0,0 +1,146 @@
+//go:build linux
+
+package system
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// ConsolePath is the terminal the application's PTY is relayed to.
+const ConsolePath = "/dev/console"
+
+// consoleDrainTimeout bounds how long Close waits for output still buffered
+// in the PTY to reach the console.
+const consoleDrainTimeout = time.Second
+
+// OpenPTY allocates a pseudo-terminal pair. The slave end is meant to become
+// a child's stdio and controlling terminal, the master end stays with init.
+func OpenPTY() (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open /dev/ptmx: %w", err)
+	}
+
+	fd := int(master.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("failed to unlock pty: %w", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("failed to get pty number: %w", err)
+	}
+
+	name := fmt.Sprintf("/dev/pts/%d", n)
+	slave, err = os.OpenFile(name, os.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	return master, slave, nil
+}
+
+// IsTerminal reports whether f is a terminal.
+func IsTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), unix.TCGETS)
+	return err == nil
+}
+
+// Console relays a PTY master to the guest console. The console is switched
+// to raw mode while attached so that line editing, echo and signal keys are
+// handled by the PTY, and its previous settings are put back by Close.
+type Console struct {
+	master  *os.File
+	console *os.File
+	saved   *unix.Termios
+	output  chan struct{}
+	once    sync.Once
+}
+
+// AttachConsole starts relaying master to the terminal at path and copies
+// the terminal's window size to the PTY.
+func AttachConsole(master *os.File, path string) (*Console, error) {
+	console, err := os.OpenFile(path, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	c := &Console{
+		master:  master,
+		console: console,
+		output:  make(chan struct{}),
+	}
+
+	// the console may not be a terminal at all, in which case there is
+	// nothing to switch to raw mode or restore
+	if saved, err := unix.IoctlGetTermios(int(console.Fd()), unix.TCGETS); err == nil {
+		raw := *saved
+		makeRaw(&raw)
+		if err := unix.IoctlSetTermios(int(console.Fd()), unix.TCSETS, &raw); err != nil {
+			log.Printf("Failed to switch %s to raw mode: %v", path, err)
+		} else {
+			c.saved = saved
+		}
+	}
+
+	c.Resize()
+
+	go func() {
+		defer close(c.output)
+		io.Copy(console, master)
+	}()
+	go io.Copy(master, console)
+
+	return c, nil
+}
+
+// Resize copies the console's window size to the PTY, which in turn sends
+// SIGWINCH to the foreground process group.
+func (c *Console) Resize() {
+	ws, err := unix.IoctlGetWinsize(int(c.console.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Row == 0 || ws.Col == 0 {
+		// serial consoles usually do not know their size
+		return
+	}
+	if err := unix.IoctlSetWinsize(int(c.master.Fd()), unix.TIOCSWINSZ, ws); err != nil {
+		log.Printf("Failed to set pty window size: %v", err)
+	}
+}
+
+// Close waits briefly for buffered output to be relayed, stops the relay and
+// restores the console settings.
+func (c *Console) Close() {
+	c.once.Do(func() {
+		select {
+		case <-c.output:
+		case <-time.After(consoleDrainTimeout):
+		}
+
+		c.master.Close()
+		if c.saved != nil {
+			if err := unix.IoctlSetTermios(int(c.console.Fd()), unix.TCSETS, c.saved); err != nil {
+				log.Printf("Failed to restore console settings: %v", err)
+			}
+		}
+		c.console.Close()
+	})
+}
+
+// makeRaw sets t to raw mode the way cfmakeraw(3) does.
+func makeRaw(t *unix.Termios) {
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB
+	t.Cflag |= unix.CS8
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+}
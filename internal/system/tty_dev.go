@@ -0,0 +1,35 @@
+//go:build !linux
+
+package system
+
+import (
+	"errors"
+	"os"
+)
+
+// ConsolePath is the terminal the application's PTY is relayed to.
+const ConsolePath = "/dev/console"
+
+// OpenPTY is a development stub for non-Linux platforms
+func OpenPTY() (master, slave *os.File, err error) {
+	return nil, nil, errors.New("pty allocation is only supported on Linux")
+}
+
+// IsTerminal is a development stub for non-Linux platforms
+func IsTerminal(f *os.File) bool {
+	return false
+}
+
+// Console is a development stub for non-Linux platforms
+type Console struct{}
+
+// AttachConsole is a development stub for non-Linux platforms
+func AttachConsole(master *os.File, path string) (*Console, error) {
+	return nil, errors.New("console relay is only supported on Linux")
+}
+
+// Resize is a development stub for non-Linux platforms
+func (c *Console) Resize() {}
+
+// Close is a development stub for non-Linux platforms
+func (c *Console) Close() {}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package system
+
+import (
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestOpenPTY(t *testing.T) {
+	master, slave, err := OpenPTY()
+	if err != nil {
+		t.Skipf("pty not available: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	// keep the slave side from echoing or translating newlines
+	termios, err := unix.IoctlGetTermios(int(slave.Fd()), unix.TCGETS)
+	if err != nil {
+		t.Fatalf("Failed to get slave termios: %v", err)
+	}
+	makeRaw(termios)
+	if err := unix.IoctlSetTermios(int(slave.Fd()), unix.TCSETS, termios); err != nil {
+		t.Fatalf("Failed to set slave termios: %v", err)
+	}
+
+	if _, err := slave.Write([]byte("hello")); err != nil {
+		t.Fatalf("Failed to write to slave: %v", err)
+	}
+	buf := make([]byte, 16)
+	n, err := master.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read from master: %v", err)
+	}
+	if string(buf[:n]) != "hello" {
+		t.Errorf("Expected 'hello' on master, got %q", buf[:n])
+	}
+}
+
+func TestConsole_Resize(t *testing.T) {
+	master, slave, err := OpenPTY()
+	if err != nil {
+		t.Skipf("pty not available: %v", err)
+	}
+	defer slave.Close()
+
+	// a second pty stands in for the console
+	consoleMaster, consoleSlave, err := OpenPTY()
+	if err != nil {
+		t.Skipf("pty not available: %v", err)
+	}
+	defer consoleMaster.Close()
+	defer consoleSlave.Close()
+
+	want := &unix.Winsize{Row: 40, Col: 120}
+	if err := unix.IoctlSetWinsize(int(consoleMaster.Fd()), unix.TIOCSWINSZ, want); err != nil {
+		t.Fatalf("Failed to set console window size: %v", err)
+	}
+
+	console, err := AttachConsole(master, consoleSlave.Name())
+	if err != nil {
+		t.Fatalf("AttachConsole failed: %v", err)
+	}
+	defer console.Close()
+
+	got, err := unix.IoctlGetWinsize(int(slave.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		t.Fatalf("Failed to get pty window size: %v", err)
+	}
+	if got.Row != want.Row || got.Col != want.Col {
+		t.Errorf("Expected window size %dx%d, got %dx%d", want.Col, want.Row, got.Col, got.Row)
+	}
+}
+
+func TestAttachConsole_MissingConsole(t *testing.T) {
+	master, slave, err := OpenPTY()
+	if err != nil {
+		t.Skipf("pty not available: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	if _, err := AttachConsole(master, os.DevNull+"-missing"); err == nil {
+		t.Error("Expected error for a missing console, got nil")
+	}
+}
@@ -0,0 +1,200 @@
+package user
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	PasswdPath = "/etc/passwd"
+	GroupPath  = "/etc/group"
+)
+
+// User is a resolved user spec, ready to be used as process credentials.
+type User struct {
+	Name   string
+	Uid    uint32
+	Gid    uint32
+	Groups []uint32
+	Home   string
+}
+
+type passwdEntry struct {
+	name string
+	uid  uint32
+	gid  uint32
+	home string
+}
+
+type groupEntry struct {
+	name    string
+	gid     uint32
+	members []string
+}
+
+// Lookup resolves spec against the rootfs /etc/passwd and /etc/group.
+func Lookup(spec string) (*User, error) {
+	return Resolve(spec, PasswdPath, GroupPath)
+}
+
+// Resolve resolves a user spec the way Docker does: "user", "uid",
+// "user:group" or "uid:gid", where names must exist in the passwd and group
+// files and numeric ids may be unknown. Supplementary groups are taken from
+// the group file only when no group is given explicitly. Missing files are
+// treated as empty so images without /etc/passwd can still run as root or
+// as a numeric uid.
+func Resolve(spec, passwdPath, groupPath string) (*User, error) {
+	userSpec, groupSpec, hasGroup := strings.Cut(spec, ":")
+	if userSpec == "" {
+		userSpec = "0"
+	}
+
+	users, err := parsePasswd(passwdPath)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &User{Home: "/"}
+	matchedName := ""
+	uid, uidErr := parseID(userSpec)
+	for _, entry := range users {
+		if entry.name == userSpec || (uidErr == nil && entry.uid == uid) {
+			u.Name = entry.name
+			u.Uid = entry.uid
+			u.Gid = entry.gid
+			u.Home = entry.home
+			matchedName = entry.name
+			break
+		}
+	}
+
+	if matchedName == "" {
+		if uidErr != nil {
+			// root is always resolvable, even without an /etc/passwd
+			if userSpec != "root" {
+				return nil, fmt.Errorf("unable to find user %s: no matching entries in %s", userSpec, passwdPath)
+			}
+			uid = 0
+		}
+		u.Uid = uid
+		u.Name = userSpec
+	}
+
+	if !hasGroup && matchedName == "" {
+		return u, nil
+	}
+
+	groups, err := parseGroup(groupPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasGroup {
+		gid, gidErr := parseID(groupSpec)
+		for _, entry := range groups {
+			if entry.name == groupSpec || (gidErr == nil && entry.gid == gid) {
+				u.Gid = entry.gid
+				return u, nil
+			}
+		}
+		if gidErr != nil {
+			return nil, fmt.Errorf("unable to find group %s: no matching entries in %s", groupSpec, groupPath)
+		}
+		u.Gid = gid
+		return u, nil
+	}
+
+	for _, entry := range groups {
+		for _, member := range entry.members {
+			if member == matchedName {
+				u.Groups = append(u.Groups, entry.gid)
+				break
+			}
+		}
+	}
+	return u, nil
+}
+
+// Credential returns the credentials to start a process with.
+func (u *User) Credential() *syscall.Credential {
+	return &syscall.Credential{
+		Uid:    u.Uid,
+		Gid:    u.Gid,
+		Groups: u.Groups,
+	}
+}
+
+func parseID(s string) (uint32, error) {
+	id, err := strconv.ParseUint(s, 10, 32)
+	return uint32(id), err
+}
+
+func parsePasswd(path string) ([]passwdEntry, error) {
+	var entries []passwdEntry
+	err := readColonFile(path, func(fields []string) {
+		// name:password:uid:gid:gecos:home:shell
+		if len(fields) < 6 {
+			return
+		}
+		uid, err := parseID(fields[2])
+		if err != nil {
+			return
+		}
+		gid, err := parseID(fields[3])
+		if err != nil {
+			return
+		}
+		entries = append(entries, passwdEntry{name: fields[0], uid: uid, gid: gid, home: fields[5]})
+	})
+	return entries, err
+}
+
+func parseGroup(path string) ([]groupEntry, error) {
+	var entries []groupEntry
+	err := readColonFile(path, func(fields []string) {
+		// name:password:gid:member,member
+		if len(fields) < 3 {
+			return
+		}
+		gid, err := parseID(fields[2])
+		if err != nil {
+			return
+		}
+		entry := groupEntry{name: fields[0], gid: gid}
+		if len(fields) > 3 && fields[3] != "" {
+			entry.members = strings.Split(fields[3], ",")
+		}
+		entries = append(entries, entry)
+	})
+	return entries, err
+}
+
+// readColonFile calls fn with the fields of every non-comment line of a
+// passwd-style file. A missing file has no entries.
+func readColonFile(path string, fn func(fields []string)) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(strings.Split(line, ":"))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPasswd = `root:x:0:0:root:/root:/bin/sh
+# comment
+app:x:1000:1000:App:/home/app:/bin/sh
+nobody:x:65534:65534:nobody:/nonexistent:/usr/sbin/nologin
+`
+
+const testGroup = `root:x:0:
+app:x:1000:
+docker:x:999:app,other
+video:x:44:app
+nogroup:x:65534:
+`
+
+func writeTestFiles(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	passwd := filepath.Join(dir, "passwd")
+	group := filepath.Join(dir, "group")
+	if err := os.WriteFile(passwd, []byte(testPasswd), 0644); err != nil {
+		t.Fatalf("Failed to write passwd: %v", err)
+	}
+	if err := os.WriteFile(group, []byte(testGroup), 0644); err != nil {
+		t.Fatalf("Failed to write group: %v", err)
+	}
+	return passwd, group
+}
+
+func TestResolve(t *testing.T) {
+	passwd, group := writeTestFiles(t)
+
+	tests := []struct {
+		name   string
+		spec   string
+		uid    uint32
+		gid    uint32
+		groups []uint32
+		home   string
+	}{
+		{name: "Empty spec is root", spec: "", uid: 0, gid: 0, home: "/root"},
+		{name: "User name", spec: "app", uid: 1000, gid: 1000, groups: []uint32{999, 44}, home: "/home/app"},
+		{name: "Known uid", spec: "1000", uid: 1000, gid: 1000, groups: []uint32{999, 44}, home: "/home/app"},
+		{name: "Unknown uid", spec: "4242", uid: 4242, gid: 0, home: "/"},
+		{name: "User and group names", spec: "app:docker", uid: 1000, gid: 999, home: "/home/app"},
+		{name: "Uid and gid", spec: "1000:44", uid: 1000, gid: 44, home: "/home/app"},
+		{name: "Unknown uid and gid", spec: "4242:4343", uid: 4242, gid: 4343, home: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := Resolve(tt.spec, passwd, group)
+			if err != nil {
+				t.Fatalf("Resolve failed: %v", err)
+			}
+			if u.Uid != tt.uid || u.Gid != tt.gid || u.Home != tt.home {
+				t.Errorf("Expected uid=%d gid=%d home=%s, got uid=%d gid=%d home=%s",
+					tt.uid, tt.gid, tt.home, u.Uid, u.Gid, u.Home)
+			}
+			if len(u.Groups) != len(tt.groups) {
+				t.Fatalf("Expected groups %v, got %v", tt.groups, u.Groups)
+			}
+			for i, g := range u.Groups {
+				if g != tt.groups[i] {
+					t.Errorf("Expected groups %v, got %v", tt.groups, u.Groups)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	passwd, group := writeTestFiles(t)
+
+	tests := []struct {
+		name     string
+		spec     string
+		errorMsg string
+	}{
+		{
+			name:     "Unknown user name",
+			spec:     "ghost",
+			errorMsg: "unable to find user ghost: no matching entries in " + passwd,
+		},
+		{
+			name:     "Unknown group name",
+			spec:     "app:ghosts",
+			errorMsg: "unable to find group ghosts: no matching entries in " + group,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Resolve(tt.spec, passwd, group)
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if err.Error() != tt.errorMsg {
+				t.Errorf("Expected error message '%s', got '%s'", tt.errorMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestResolve_MissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	passwd := filepath.Join(dir, "passwd")
+	group := filepath.Join(dir, "group")
+
+	u, err := Resolve("root", passwd, group)
+	if err != nil {
+		t.Fatalf("Expected root to resolve without passwd file, got: %v", err)
+	}
+	if u.Uid != 0 || u.Gid != 0 || u.Home != "/" {
+		t.Errorf("Expected uid 0, gid 0 and home /, got %+v", u)
+	}
+
+	if _, err := Resolve("app", passwd, group); err == nil {
+		t.Error("Expected error for named user without passwd file, got nil")
+	}
+}
+
+func TestUser_Credential(t *testing.T) {
+	u := &User{Uid: 1000, Gid: 1000, Groups: []uint32{44}}
+
+	cred := u.Credential()
+	if cred.Uid != 1000 || cred.Gid != 1000 || len(cred.Groups) != 1 || cred.Groups[0] != 44 {
+		t.Errorf("Unexpected credential %+v", cred)
+	}
+}
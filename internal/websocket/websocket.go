@@ -6,8 +6,8 @@ import (
 	"net/http"
 	"os/exec"
 	"sync"
-	"syscall"
 
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
 	"github.com/gorilla/websocket"
 )
 
@@ -25,8 +25,10 @@ type WSMessage struct {
 type WSConnection struct {
 	conn      *websocket.Conn
 	cmd       *exec.Cmd
-	mutex     *sync.Mutex
-	envs      map[string]string
+	process   *reaper.Process
+	reaper    *reaper.Reaper
+	writeMu   sync.Mutex
+	env       []string
 	active    bool
 	stdinPipe io.WriteCloser
 }
@@ -39,11 +41,11 @@ var upgrader = websocket.Upgrader{
 	WriteBufferSize: 1024,
 }
 
-func NewWSConnection(conn *websocket.Conn, envs map[string]string, mutex *sync.Mutex) *WSConnection {
+func NewWSConnection(conn *websocket.Conn, env []string, r *reaper.Reaper) *WSConnection {
 	return &WSConnection{
 		conn:   conn,
-		mutex:  mutex,
-		envs:   envs,
+		reaper: r,
+		env:    env,
 		active: true,
 	}
 }
@@ -78,45 +80,44 @@ func (ws *WSConnection) startTTYProcess(msg WSMessage) error {
 }
 
 func (ws *WSConnection) startRegularProcess(msg WSMessage) error {
-	
+
 	ws.cmd = exec.Command(msg.Cmd[0], msg.Cmd[1:]...)
-	
-	env := make([]string, 0, len(ws.envs))
-	for k, v := range ws.envs {
-		env = append(env, k+"="+v)
-	}
-	ws.cmd.Env = env
-	
+	ws.cmd.Env = append([]string{}, ws.env...)
+
 	stdin, err := ws.cmd.StdinPipe()
 	if err != nil {
 		return ws.sendError("Failed to create stdin pipe: " + err.Error())
 	}
-	
+
 	stdout, err := ws.cmd.StdoutPipe()
 	if err != nil {
 		stdin.Close()
 		return ws.sendError("Failed to create stdout pipe: " + err.Error())
 	}
-	
+
 	stderr, err := ws.cmd.StderrPipe()
 	if err != nil {
 		stdin.Close()
 		stdout.Close()
 		return ws.sendError("Failed to create stderr pipe: " + err.Error())
 	}
-	
-	if err := ws.cmd.Start(); err != nil {
+
+	process, err := ws.reaper.Start(ws.cmd)
+	if err != nil {
 		stdin.Close()
 		stdout.Close()
 		stderr.Close()
 		return ws.sendError("Failed to start process: " + err.Error())
 	}
-	
-	
-	go ws.streamOutput(stdout, "stdout")
-	go ws.streamOutput(stderr, "stderr")
-	go ws.handleProcessCompletion(stdin, stdout, stderr)
-	
+	ws.process = process
+	ws.stdinPipe = stdin
+
+	var streams sync.WaitGroup
+	streams.Add(2)
+	go ws.streamOutput(stdout, "stdout", &streams)
+	go ws.streamOutput(stderr, "stderr", &streams)
+	go ws.handleProcessCompletion(stdin, stdout, stderr, &streams)
+
 	return nil
 }
 
@@ -124,12 +125,12 @@ func (ws *WSConnection) handleStdin(msg WSMessage) error {
 	if ws.cmd == nil || ws.stdinPipe == nil {
 		return ws.sendError("No active process")
 	}
-	
+
 	_, err := ws.stdinPipe.Write([]byte(msg.Data))
 	if err != nil {
-			return ws.sendError("Failed to write to process stdin")
+		return ws.sendError("Failed to write to process stdin")
 	}
-	
+
 	return nil
 }
 
@@ -138,8 +139,8 @@ func (ws *WSConnection) handleResize(msg WSMessage) error {
 }
 
 func (ws *WSConnection) sendMessage(msg WSMessage) error {
-	ws.mutex.Lock()
-	defer ws.mutex.Unlock()
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
 	return ws.conn.WriteJSON(msg)
 }
 
@@ -171,9 +172,10 @@ func (ws *WSConnection) sendError(errMsg string) error {
 	})
 }
 
-func (ws *WSConnection) streamOutput(reader io.Reader, outputType string) {
+func (ws *WSConnection) streamOutput(reader io.Reader, outputType string, streams *sync.WaitGroup) {
+	defer streams.Done()
 	buffer := make([]byte, 65536)
-	
+
 	for ws.active {
 		n, err := reader.Read(buffer)
 		if n > 0 {
@@ -184,62 +186,53 @@ func (ws *WSConnection) streamOutput(reader io.Reader, outputType string) {
 				ws.sendStderr(data)
 			}
 		}
-		
+
 		if err != nil {
 			break
 		}
 	}
 }
 
-func (ws *WSConnection) handleProcessCompletion(stdin io.WriteCloser, stdout, stderr io.ReadCloser) {
-	ws.stdinPipe = stdin
-	
-	err := ws.cmd.Wait()
-	
+func (ws *WSConnection) handleProcessCompletion(stdin io.WriteCloser, stdout, stderr io.ReadCloser, streams *sync.WaitGroup) {
+	status := ws.process.Wait()
+	// drain whatever the process wrote before exiting
+	streams.Wait()
+
 	stdin.Close()
 	stdout.Close()
 	stderr.Close()
-	
+
 	var exitCode *int
 	var signal *int
-	
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
-				if status.Exited() {
-					code := status.ExitStatus()
-					exitCode = &code
-				} else if status.Signaled() {
-					sig := int(status.Signal())
-					signal = &sig
-				}
-			}
-		}
-	} else {
-		code := 0
+
+	if status.Exited() {
+		code := status.ExitStatus()
 		exitCode = &code
+	} else if status.Signaled() {
+		sig := int(status.Signal())
+		signal = &sig
 	}
-	
+
 	exitMsg := WSMessage{
-		Type: "exit",
-		Code: exitCode,
+		Type:   "exit",
+		Code:   exitCode,
 		Signal: signal,
 	}
-	
+
 	ws.sendMessage(exitMsg)
 }
 
 func (ws *WSConnection) cleanup() {
 	ws.active = false
-	
+
 	if ws.stdinPipe != nil {
 		ws.stdinPipe.Close()
 	}
-	
+
 	if ws.cmd != nil && ws.cmd.Process != nil {
 		ws.cmd.Process.Kill()
 	}
-	
+
 	ws.conn.Close()
 }
 
@@ -263,14 +256,13 @@ func (ws *WSConnection) run() {
 	}
 }
 
-func HandleWSExec(w http.ResponseWriter, r *http.Request, envs map[string]string, mutex *sync.Mutex) {
+func HandleWSExec(w http.ResponseWriter, r *http.Request, env []string, rp *reaper.Reaper) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade failed: %v", err)
 		return
 	}
 
-	wsConn := NewWSConnection(conn, envs, mutex)
+	wsConn := NewWSConnection(conn, env, rp)
 	wsConn.run()
 }
-
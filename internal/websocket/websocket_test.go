@@ -5,9 +5,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
-	"sync"
 	"testing"
 
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper"
+	"github.com/TheRealSibasishBehera/init-go/internal/reaper/reapertest"
 	"github.com/gorilla/websocket"
 )
 
@@ -69,10 +70,10 @@ func TestWSMessageTypes(t *testing.T) {
 }
 
 func TestWebSocketUpgrade(t *testing.T) {
+	rp := reapertest.Reaper()
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		envs := map[string]string{"PATH": "/bin:/usr/bin"}
-		mutex := &sync.Mutex{}
-		HandleWSExec(w, r, envs, mutex)
+		env := []string{"PATH=/bin:/usr/bin"}
+		HandleWSExec(w, r, env, rp)
 	}))
 	defer server.Close()
 
@@ -111,17 +112,15 @@ func TestWebSocketUpgrade(t *testing.T) {
 }
 
 func TestWSConnectionCreation(t *testing.T) {
-	envs := map[string]string{"TEST": "value"}
-	mutex := &sync.Mutex{}
-
-	wsConn := NewWSConnection(nil, envs, mutex)
+	env := []string{"TEST=value"}
+	wsConn := NewWSConnection(nil, env, reaper.New())
 
 	if wsConn == nil {
 		t.Fatal("WSConnection should not be nil")
 	}
 
-	if wsConn.envs["TEST"] != "value" {
-		t.Errorf("Expected TEST=value, got %s", wsConn.envs["TEST"])
+	if len(wsConn.env) != 1 || wsConn.env[0] != "TEST=value" {
+		t.Errorf("Expected TEST=value, got %v", wsConn.env)
 	}
 
 	if !wsConn.active {
@@ -129,3 +128,45 @@ func TestWSConnectionCreation(t *testing.T) {
 	}
 }
 
+func TestWebSocketExec_OutputBeforeExit(t *testing.T) {
+	rp := reapertest.Reaper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HandleWSExec(w, r, []string{"PATH=/bin:/usr/bin"}, rp)
+	}))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to WebSocket: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(WSMessage{Type: "init", Cmd: []string{"sh", "-c", "echo out; exit 3"}}); err != nil {
+		t.Fatalf("Failed to send init message: %v", err)
+	}
+
+	var stdout string
+	for {
+		var msg WSMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		if msg.Type == "stdout" {
+			stdout += msg.Data
+			continue
+		}
+		if msg.Type != "exit" {
+			t.Fatalf("Expected stdout or exit message, got: %s", msg.Type)
+		}
+		if msg.Code == nil || *msg.Code != 3 {
+			t.Errorf("Expected exit code 3, got %v", msg.Code)
+		}
+		break
+	}
+
+	if stdout != "out\n" {
+		t.Errorf("Expected stdout 'out\\n' before exit, got '%s'", stdout)
+	}
+}